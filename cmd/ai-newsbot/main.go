@@ -1,120 +1,142 @@
+// Command ai-newsbot runs the news pipeline: it fetches posts from the
+// configured sources, translates the best new ones into every configured
+// language and publishes them to each language's Telegram channel.
+//
+//	ai-newsbot [-once] [-schedule "@hourly"]
+//
+// It runs the pipeline on start and then on the schedule, until stopped.
+// Settings are read from the environment and an optional .env file.
 package main
 
 import (
 	"context"
-	"fmt"
+	"flag"
 	"log"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
+	"github.com/joho/godotenv"
+	"github.com/robfig/cron/v3"
+
+	"github.com/w1zzzle/ai-newsbot/internal/app"
+	"github.com/w1zzzle/ai-newsbot/internal/bot"
+	"github.com/w1zzzle/ai-newsbot/internal/config"
+	"github.com/w1zzzle/ai-newsbot/internal/dedup"
+	"github.com/w1zzzle/ai-newsbot/internal/ranking"
+	"github.com/w1zzzle/ai-newsbot/internal/rules"
+	"github.com/w1zzzle/ai-newsbot/internal/scraper"
+	"github.com/w1zzzle/ai-newsbot/internal/storage"
 	"github.com/w1zzzle/ai-newsbot/internal/translation"
 )
 
 func main() {
-	// Get API key from environment
-	apiKey := os.Getenv("OPENROUTER_API_KEY")
-	if apiKey == "" {
-		log.Fatal("OPENROUTER_API_KEY environment variable is required")
-	}
-
-	// Create translator
-	translator := translation.New(apiKey)
+	once := flag.Bool("once", false, "run the pipeline once and exit")
+	schedule := flag.String("schedule", "@hourly", "cron schedule of pipeline runs")
+	timeout := flag.Duration("timeout", 30*time.Minute, "time limit of a single run")
+	flag.Parse()
 
-	// Test translation service health
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+	// A missing .env is fine, the environment may already be set
+	_ = godotenv.Load()
 
-	if err := translator.IsHealthy(ctx); err != nil {
-		log.Printf("Translation service health check failed: %v", err)
-	} else {
-		log.Println("Translation service is healthy")
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
 	}
 
-	// Example usage in your scraping workflow
-	redditPost := `
-Title: New AI Model Breaks Performance Records
-
-Content: Researchers at OpenAI have announced a breakthrough in large language model performance. The new model, called GPT-5, shows significant improvements in reasoning, coding, and multilingual capabilities.
-
-Key features:
-- 50% better performance on coding benchmarks
-- Improved reasoning abilities
-- Better multilingual support
-- More efficient training process
-
-The model is expected to be released later this year after safety testing.
-	`
+	store, err := storage.NewPostgresStore(cfg.PostgresDSN)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer store.Close()
 
-	// Translate the post
-	translatedPost, err := translator.TranslateToRussian(ctx, redditPost)
+	newsbot, err := newApp(cfg, store)
 	if err != nil {
-		log.Fatalf("Translation failed: %v", err)
+		log.Fatal(err)
 	}
 
-	fmt.Println("Original post:")
-	fmt.Println(redditPost)
-	fmt.Println("\nTranslated post:")
-	fmt.Println(translatedPost)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	// Example batch translation
-	posts := []string{
-		"Breaking: New programming language announced",
-		"Tech stocks surge after AI breakthrough",
-		"Open source project gains massive community support",
+	run := func() {
+		runCtx, cancel := context.WithTimeout(ctx, *timeout)
+		defer cancel()
+		if err := newsbot.RunPipeline(runCtx); err != nil {
+			log.Printf("Pipeline failed: %v", err)
+		}
 	}
 
-	translatedPosts, err := translator.TranslateBatch(ctx, posts)
-	if err != nil {
-		log.Fatalf("Batch translation failed: %v", err)
+	run()
+	if *once {
+		return
 	}
 
-	fmt.Println("\nBatch translation results:")
-	for i, original := range posts {
-		fmt.Printf("Original: %s\n", original)
-		fmt.Printf("Russian: %s\n\n", translatedPosts[i])
+	scheduler := cron.New()
+	if _, err := scheduler.AddFunc(*schedule, run); err != nil {
+		log.Fatalf("Invalid schedule %q: %v", *schedule, err)
 	}
+	scheduler.Start()
+	log.Printf("Running on schedule %s", *schedule)
+
+	<-ctx.Done()
+	log.Println("Shutting down...")
+	<-scheduler.Stop().Done()
 }
 
-// Example integration with your scraping workflow
-func processRedditPost(translator translation.Service, post RedditPost) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+// newApp builds the pipeline from cfg
+func newApp(cfg *config.Config, store *storage.PostgresStore) (*app.App, error) {
+	var scraperOpts []scraper.Option
+	if cfg.HTTPCachePostgres {
+		scraperOpts = append(scraperOpts, scraper.WithValidatorStore(store))
+	}
+	postScraper := scraper.FromConfig(cfg, store, scraperOpts...)
 
-	// Combine title and content for translation
-	fullText := fmt.Sprintf("Заголовок: %s\n\nСодержание: %s", post.Title, post.Content)
+	translator, err := translation.FromConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
 
-	// Translate to Russian
-	translatedText, err := translator.TranslateToRussian(ctx, fullText)
+	var botOpts []bot.Option
+	if cfg.TelegramAdminChatID != 0 {
+		botOpts = append(botOpts, bot.WithAdminChat(cfg.TelegramAdminChatID))
+	}
+	telegram, err := bot.New(cfg.TelegramBotToken, cfg.TelegramChatID, botOpts...)
 	if err != nil {
-		return fmt.Errorf("failed to translate post: %w", err)
+		return nil, err
 	}
 
-	// Parse translated text back to title and content if needed
-	// This is a simple example - you might want more sophisticated parsing
-	post.TranslatedTitle = extractTitleFromTranslation(translatedText)
-	post.TranslatedContent = extractContentFromTranslation(translatedText)
+	opts := []app.Option{
+		app.WithLanguages(cfg.TranslationLanguages...),
+		app.WithRanker(ranking.New(ranking.Weights{
+			Score:    cfg.RankScoreWeight,
+			Comments: cfg.RankCommentWeight,
+		}, cfg.RankTopN)),
+		app.WithDuplicateDetector(dedup.NewDetector(store, dedup.NewResolver(nil))),
+		app.WithTopComments(cfg.TopComments),
+		app.WithFailureAlertAfter(cfg.SourceFailureAlertAfter),
+		app.WithHealthMonitor(scraper.NewHealthMonitor(store), telegram),
+	}
 
-	return nil
-}
+	// The first language goes to TELEGRAM_CHAT_ID, the others to their own
+	for lang, chatID := range cfg.TelegramChatIDs {
+		opts = append(opts, app.WithChannel(lang, telegram.ForChat(chatID)))
+	}
 
-// Mock structures for example
-type RedditPost struct {
-	Title             string
-	Content           string
-	URL               string
-	Upvotes          int
-	TranslatedTitle   string
-	TranslatedContent string
-}
+	if cfg.FollowLinks {
+		opts = append(opts, app.WithArticleExtractor(scraper.NewArticleExtractor(
+			scraper.WithUserAgent(cfg.ScraperUserAgent),
+			scraper.WithTimeout(time.Duration(cfg.ScraperTimeoutSeconds)*time.Second),
+		)))
+	}
 
-func extractTitleFromTranslation(translatedText string) string {
-	// Implementation would parse "Заголовок: ..." from translated text
-	// This is a placeholder
-	return "Translated Title"
-}
+	if cfg.RulesFile != "" {
+		engine, err := rules.Load(cfg.RulesFile)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, app.WithRules(engine))
+	}
 
-func extractContentFromTranslation(translatedText string) string {
-	// Implementation would parse "Содержание: ..." from translated text
-	// This is a placeholder
-	return "Translated Content"
-}
\ No newline at end of file
+	return app.New(store, postScraper, translator, telegram, opts...), nil
+}
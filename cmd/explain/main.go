@@ -0,0 +1,100 @@
+// Command explain evaluates a post against the content rules and shows
+// which rules matched and why.
+//
+//	explain [-rules rules.json] [-lang ru] <post-id>
+//
+// The post is read from the database when POSTGRES_DSN is set and it was
+// saved, along with its translation into -lang; otherwise Reddit posts are
+// fetched by their ID.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/w1zzzle/ai-newsbot/internal/rules"
+	"github.com/w1zzzle/ai-newsbot/internal/scraper"
+	"github.com/w1zzzle/ai-newsbot/internal/storage"
+	"github.com/w1zzzle/ai-newsbot/internal/translation"
+)
+
+func main() {
+	rulesFile := flag.String("rules", os.Getenv("RULES_FILE"), "rules file")
+	lang := flag.String("lang", translation.DefaultLanguage, "language of the translation to show")
+	timeout := flag.Duration("timeout", time.Minute, "lookup timeout")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [-rules file] <post id>\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *rulesFile == "" || flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	engine, err := rules.Load(*rulesFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	post, err := findPost(ctx, flag.Arg(0), *lang)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("Post %s: %s\n", post.RedditID, post.Title)
+	if post.URL != "" {
+		fmt.Printf("Link: %s\n", post.URL)
+	}
+	if post.TranslatedBody != "" {
+		fmt.Printf("Translation (%s): %s\n", *lang, post.TranslatedBody)
+	}
+
+	evaluation := engine.Evaluate(post)
+	if evaluation.Matched {
+		fmt.Printf("Kept, matched rules: %s\n", strings.Join(evaluation.MatchedRules(), ", "))
+	} else {
+		fmt.Printf("Dropped, %s\n", evaluation.Reason())
+	}
+	fmt.Printf("\n%s\n", evaluation)
+}
+
+// findPost reads a saved post with its translation into lang, falling back
+// to fetching it from Reddit
+func findPost(ctx context.Context, id, lang string) (storage.Post, error) {
+	if dsn := os.Getenv("POSTGRES_DSN"); dsn != "" {
+		store, err := storage.NewPostgresStore(dsn)
+		if err != nil {
+			return storage.Post{}, fmt.Errorf("failed to connect to database: %w", err)
+		}
+		defer store.Close()
+
+		post, found, err := store.GetPost(ctx, id, lang)
+		if err != nil {
+			return storage.Post{}, fmt.Errorf("failed to load post: %w", err)
+		}
+		if found {
+			return post, nil
+		}
+	}
+
+	// Other sources namespace their IDs and can't be fetched one by one
+	if strings.Contains(id, ":") {
+		return storage.Post{}, fmt.Errorf("post %s is not saved", id)
+	}
+
+	post, err := scraper.NewJSON(nil, 0, scraper.WithMaxRetries(0)).FetchPost(ctx, id)
+	if err != nil {
+		return storage.Post{}, fmt.Errorf("failed to fetch post from Reddit: %w", err)
+	}
+	return post, nil
+}
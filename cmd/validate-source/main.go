@@ -0,0 +1,128 @@
+// Command validate-source fetches one page of a configured source and
+// prints what was extracted from it, to debug selectors and filters.
+//
+//	validate-source [-sources sources.json] [-record dir | -replay dir] <url>
+//
+// -record saves every HTTP exchange as a fixture in dir and -replay serves
+// them from dir without touching the network, to reproduce a bad parse.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/w1zzzle/ai-newsbot/internal/config"
+	"github.com/w1zzzle/ai-newsbot/internal/scraper"
+	"github.com/w1zzzle/ai-newsbot/internal/storage"
+)
+
+func main() {
+	sourcesFile := flag.String("sources", os.Getenv("SOURCES_FILE"), "sources file")
+	timeout := flag.Duration("timeout", time.Minute, "fetch timeout")
+	recordDir := flag.String("record", "", "record HTTP fixtures into this directory")
+	replayDir := flag.String("replay", "", "replay HTTP fixtures from this directory")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [-sources file] [-record dir | -replay dir] <source url>\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *sourcesFile == "" || flag.NArg() != 1 || (*recordDir != "" && *replayDir != "") {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	opts := []scraper.Option{scraper.WithMaxRetries(0)}
+	switch {
+	case *recordDir != "":
+		opts = append(opts, scraper.WithFixtures(scraper.FixtureRecord, *recordDir))
+	case *replayDir != "":
+		opts = append(opts, scraper.WithFixtures(scraper.FixtureReplay, *replayDir))
+	}
+
+	sources, err := config.LoadSources(*sourcesFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	source, ok := findSource(sources, flag.Arg(0))
+	if !ok {
+		log.Fatalf("No source with url %s in %s", flag.Arg(0), *sourcesFile)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	fmt.Printf("Source: %s (%s)\n", source.URL, source.Type)
+
+	var posts []storage.Post
+	if source.Type == config.SourceHTML {
+		extraction, err := scraper.NewHTML(nil, opts...).Extract(ctx, source)
+		if err != nil {
+			log.Fatalf("Fetch failed: %v", err)
+		}
+		fmt.Printf("Containers matched: %d, skipped: %d\n", extraction.Containers, extraction.Skipped)
+		posts = extraction.Posts
+	} else {
+		cfg := &config.Config{Sources: []config.Source{source}}
+		result, ok := scraper.FromConfig(cfg, nil, opts...).(scraper.ResultFetcher)
+		if !ok {
+			log.Fatalf("Source type %s doesn't report results", source.Type)
+		}
+		for _, r := range result.FetchResults(ctx) {
+			if r.Err != nil {
+				log.Fatalf("Fetch failed: %v", r.Err)
+			}
+			if r.BelowThreshold > 0 {
+				fmt.Printf("Below threshold: %d\n", r.BelowThreshold)
+			}
+			if r.Filtered > 0 {
+				fmt.Printf("Filtered by flair or flags: %d\n", r.Filtered)
+			}
+			posts = append(posts, r.Posts...)
+		}
+	}
+
+	fmt.Printf("Posts extracted: %d\n", len(posts))
+	for i, post := range posts {
+		printPost(i+1, post)
+	}
+}
+
+func findSource(sources []config.Source, url string) (config.Source, bool) {
+	for _, source := range sources {
+		if source.URL == url {
+			return source, true
+		}
+	}
+	return config.Source{}, false
+}
+
+func printPost(n int, post storage.Post) {
+	fmt.Printf("\n#%d %s\n", n, post.Title)
+	fmt.Printf("  id:      %s\n", post.RedditID)
+	fmt.Printf("  score:   %d\n", post.Score)
+	fmt.Printf("  date:    %s\n", post.CreatedAt.Format(time.RFC3339))
+	if post.Permalink != "" {
+		fmt.Printf("  link:    %s\n", post.Permalink)
+	}
+	for _, media := range post.MediaURLs {
+		fmt.Printf("  media:   %s\n", media)
+	}
+	if post.Body != "" {
+		fmt.Printf("  body:    %s\n", excerpt(post.Body, 200))
+	}
+}
+
+func excerpt(text string, n int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if runes := []rune(text); len(runes) > n {
+		return string(runes[:n]) + "..."
+	}
+	return text
+}
@@ -4,8 +4,13 @@ import (
     "context"
     "fmt"
     "log"
+    "strings"
+    "sync"
 
     "github.com/w1zzzle/ai-newsbot/internal/bot"
+    "github.com/w1zzzle/ai-newsbot/internal/dedup"
+    "github.com/w1zzzle/ai-newsbot/internal/ranking"
+    "github.com/w1zzzle/ai-newsbot/internal/rules"
     "github.com/w1zzzle/ai-newsbot/internal/scraper"
     "github.com/w1zzzle/ai-newsbot/internal/storage"
     "github.com/w1zzzle/ai-newsbot/internal/translation"
@@ -14,32 +19,144 @@ import (
 type App struct {
     store      storage.Store
     scraper    scraper.Scraper
-    translator translation.Translator
+    translator translation.Service
     bot        bot.Bot
+    ranker     *ranking.Ranker
+    rules      *rules.Engine
+    duplicates *dedup.Detector
+
+    // languages are translated for every post; the first is published by
+    // bot unless it has a channel of its own
+    languages []string
+    channels  map[string]bot.Bot
+
+    topComments int
+    articles    *scraper.ArticleExtractor
+
+    failures          *scraper.FailureTracker
+    failureAlertAfter int
+
+    health  *scraper.HealthMonitor
+    alerter bot.Alerter
+
+    // running keeps a slow run from overlapping the next scheduled one
+    running sync.Mutex
+}
+
+// Option configures optional pipeline stages
+type Option func(*App)
+
+// WithFailureAlertAfter sets how many runs in a row a source may fail
+// before the pipeline raises an alert for it
+func WithFailureAlertAfter(runs int) Option {
+    return func(a *App) {
+        a.failureAlertAfter = runs
+    }
+}
+
+// WithHealthMonitor checks every run for sources that stopped yielding
+// posts and sends an alert through alerter when one degrades
+func WithHealthMonitor(monitor *scraper.HealthMonitor, alerter bot.Alerter) Option {
+    return func(a *App) {
+        a.health = monitor
+        a.alerter = alerter
+    }
+}
+
+// WithTopComments attaches the k top comments to every selected post, if
+// the scraper can fetch them
+func WithTopComments(k int) Option {
+    return func(a *App) {
+        a.topComments = k
+    }
+}
+
+// WithArticleExtractor fills link posts that have no text of their own
+// with the article they link to
+func WithArticleExtractor(extractor *scraper.ArticleExtractor) Option {
+    return func(a *App) {
+        a.articles = extractor
+    }
+}
+
+// WithRules drops fetched posts the rules engine doesn't allow
+func WithRules(engine *rules.Engine) Option {
+    return func(a *App) {
+        a.rules = engine
+    }
+}
+
+// WithDuplicateDetector merges new posts telling a story already saved
+// from another source into it, instead of publishing them again
+func WithDuplicateDetector(detector *dedup.Detector) Option {
+    return func(a *App) {
+        a.duplicates = detector
+    }
 }
 
-func New(store storage.Store, scraper scraper.Scraper, translator translation.Translator, bot bot.Bot) *App {
-    return &App{
-        store:      store,
-        scraper:    scraper,
-        translator: translator,
-        bot:        bot,
+// WithLanguages translates every post into each of the given languages
+// instead of only translation.DefaultLanguage
+func WithLanguages(languages ...string) Option {
+    return func(a *App) {
+        if len(languages) > 0 {
+            a.languages = languages
+        }
+    }
+}
+
+// WithChannel publishes the translations into lang through b
+func WithChannel(lang string, b bot.Bot) Option {
+    return func(a *App) {
+        if a.channels == nil {
+            a.channels = make(map[string]bot.Bot)
+        }
+        a.channels[lang] = b
     }
 }
 
+// WithRanker limits translation to the highest-ranked new posts of a run
+func WithRanker(ranker *ranking.Ranker) Option {
+    return func(a *App) {
+        a.ranker = ranker
+    }
+}
+
+func New(store storage.Store, postScraper scraper.Scraper, translator translation.Service, bot bot.Bot, opts ...Option) *App {
+    a := &App{
+        store:             store,
+        scraper:           postScraper,
+        translator:        translator,
+        bot:               bot,
+        languages:         []string{translation.DefaultLanguage},
+        failures:          scraper.NewFailureTracker(),
+        failureAlertAfter: 3,
+    }
+    for _, opt := range opts {
+        opt(a)
+    }
+    return a
+}
+
 func (a *App) RunPipeline(ctx context.Context) error {
+    // Overlapping runs would translate and publish the same posts twice
+    if !a.running.TryLock() {
+        log.Println("Previous pipeline run is still in progress, skipping this one")
+        return nil
+    }
+    defer a.running.Unlock()
+
     log.Println("Starting AI NewsBot pipeline...")
 
     // Step 1: Fetch posts from Reddit
     log.Println("Fetching posts from Reddit...")
-    posts, err := a.scraper.FetchPosts(ctx)
+    posts, results, err := a.fetchPosts(ctx)
     if err != nil {
         return fmt.Errorf("failed to fetch posts: %w", err)
     }
     log.Printf("Fetched %d posts", len(posts))
 
-    // Step 2: Filter new posts and translate them
-    newPosts := 0
+    // Step 2: Filter new posts
+    var candidates []storage.Post
     for _, post := range posts {
         // Check if we've seen this post before
         seen, err := a.store.IsPostSeen(ctx, post.RedditID)
@@ -52,50 +169,306 @@ func (a *App) RunPipeline(ctx context.Context) error {
             continue
         }
 
+        candidates = append(candidates, post)
+    }
+
+    // The same story often arrives from several sources at once
+    if a.duplicates != nil {
+        unique := a.duplicates.Filter(ctx, candidates)
+        log.Printf("%d of %d new posts are new stories", len(unique), len(candidates))
+        candidates = unique
+    }
+
+    // Step 3: Read linked articles, so body rules see their text, and
+    // apply content rules
+    a.expandLinks(ctx, candidates)
+    candidates = a.applyRules(candidates)
+
+    // Posts that are new but not saved yet; their sources must not be
+    // cached as unchanged until they are
+    unsaved := make(map[string]bool, len(candidates))
+    for _, post := range candidates {
+        unsaved[post.RedditID] = true
+    }
+
+    // Step 4: Rank candidates so only the top ones reach the translator
+    if a.ranker != nil {
+        ranked := a.ranker.Select(candidates)
+        log.Printf("Selected %d of %d new posts by rank", len(ranked), len(candidates))
+        candidates = ranked
+    }
+
+    // Step 5: Quote the community's reaction
+    a.attachComments(ctx, candidates)
+
+    // Step 6: Translate and save
+    newPosts := 0
+    for _, post := range candidates {
         // Translate the post
         log.Printf("Translating post: %s", post.Title)
-        translatedBody, err := a.translator.TranslateToRussian(ctx, post.Body)
-        if err != nil {
-            log.Printf("Failed to translate post %s: %v", post.RedditID, err)
+        if !a.translatePost(ctx, &post) {
             continue
         }
 
-        post.TranslatedBody = translatedBody
-
         // Save the post
         if err := a.store.SavePost(ctx, post); err != nil {
             log.Printf("Failed to save post %s: %v", post.RedditID, err)
             continue
         }
 
+        delete(unsaved, post.RedditID)
         newPosts++
     }
 
     log.Printf("Processed %d new posts", newPosts)
+    commitCaches(ctx, results, unsaved)
+
+    // Step 7: Publish unpublished posts in every language
+    published := 0
+    for _, lang := range a.languages {
+        n, err := a.publish(ctx, lang)
+        if err != nil {
+            return err
+        }
+        published += n
+    }
 
-    // Step 3: Publish unpublished posts
-    log.Println("Publishing unpublished posts...")
-    unpublishedPosts, err := a.store.ListUnpublishedPosts(ctx)
+    log.Printf("Pipeline completed. Published %d posts", published)
+    return nil
+}
+
+// publish sends the posts not yet published in lang to its channel
+func (a *App) publish(ctx context.Context, lang string) (int, error) {
+    channel := a.channel(lang)
+    if channel == nil {
+        log.Printf("No channel for language %s, not publishing it", lang)
+        return 0, nil
+    }
+
+    log.Printf("Publishing unpublished posts in %s...", lang)
+    unpublishedPosts, err := a.store.ListUnpublishedPosts(ctx, lang)
     if err != nil {
-        return fmt.Errorf("failed to list unpublished posts: %w", err)
+        return 0, fmt.Errorf("failed to list unpublished posts in %s: %w", lang, err)
     }
 
     published := 0
     for _, post := range unpublishedPosts {
-        if err := a.bot.SendPost(ctx, post); err != nil {
-            log.Printf("Failed to send post %s: %v", post.RedditID, err)
+        if err := channel.SendPost(ctx, post); err != nil {
+            log.Printf("Failed to send post %s in %s: %v", post.RedditID, lang, err)
             continue
         }
 
-        if err := a.store.MarkPublished(ctx, post.RedditID); err != nil {
-            log.Printf("Failed to mark post %s as published: %v", post.RedditID, err)
+        if err := a.store.MarkPublished(ctx, post.RedditID, lang); err != nil {
+            log.Printf("Failed to mark post %s as published in %s: %v", post.RedditID, lang, err)
             continue
         }
 
         published++
-        log.Printf("Published post: %s", post.Title)
+        log.Printf("Published post in %s: %s", lang, post.Title)
     }
 
-    log.Printf("Pipeline completed. Published %d posts", published)
+    return published, nil
+}
+
+// channel returns the bot publishing lang, or nil if there is none
+func (a *App) channel(lang string) bot.Bot {
+    if channel, ok := a.channels[lang]; ok {
+        return channel
+    }
+    if lang == a.languages[0] {
+        return a.bot
+    }
     return nil
-}
\ No newline at end of file
+}
+
+// applyRules keeps the posts the rules engine allows, when configured,
+// logging why each other post was dropped
+func (a *App) applyRules(posts []storage.Post) []storage.Post {
+    if a.rules == nil {
+        return posts
+    }
+
+    var kept []storage.Post
+    for _, post := range posts {
+        evaluation := a.rules.Evaluate(post)
+        if !evaluation.Matched {
+            log.Printf("Skipping post %s: %s", post.RedditID, evaluation.Reason())
+            continue
+        }
+        kept = append(kept, post)
+    }
+
+    log.Printf("Rules kept %d of %d posts", len(kept), len(posts))
+    return kept
+}
+
+// expandLinks extracts the linked article of every link post without a
+// body, when enabled
+func (a *App) expandLinks(ctx context.Context, posts []storage.Post) {
+    if a.articles == nil {
+        return
+    }
+
+    for i := range posts {
+        if err := a.articles.Expand(ctx, &posts[i]); err != nil {
+            log.Printf("Failed to extract article for post %s: %v", posts[i].RedditID, err)
+        }
+    }
+}
+
+// attachComments fetches the top comments of each post, when enabled and
+// supported by the scraper
+func (a *App) attachComments(ctx context.Context, posts []storage.Post) {
+    fetcher, ok := a.scraper.(scraper.CommentFetcher)
+    if !ok || a.topComments <= 0 {
+        return
+    }
+
+    for i := range posts {
+        comments, err := fetcher.FetchComments(ctx, posts[i], a.topComments)
+        if err != nil {
+            log.Printf("Failed to fetch comments of post %s: %v", posts[i].RedditID, err)
+            continue
+        }
+        posts[i].TopComments = comments
+    }
+}
+
+// translatePost translates the body and comments of a post into every
+// language. Link posts often have no body, so a post is kept as long as
+// something translated in at least one language.
+func (a *App) translatePost(ctx context.Context, post *storage.Post) bool {
+    post.Translations = make(map[string]storage.Translation)
+    for _, lang := range a.languages {
+        if t, ok := a.translateInto(ctx, *post, lang); ok {
+            post.Translations[lang] = t
+        }
+    }
+
+    if len(post.Translations) == 0 {
+        log.Printf("Skipping post %s: nothing to translate", post.RedditID)
+        return false
+    }
+    return true
+}
+
+// translateInto translates the body and comments of a post into lang. A
+// failed body drops the language; failed comments are left out.
+func (a *App) translateInto(ctx context.Context, post storage.Post, lang string) (storage.Translation, bool) {
+    var t storage.Translation
+
+    if strings.TrimSpace(post.Body) != "" {
+        translatedBody, err := a.translator.Translate(ctx, post.Body, lang)
+        if err != nil {
+            log.Printf("Failed to translate post %s into %s: %v", post.RedditID, lang, err)
+            return t, false
+        }
+        t.Body = translatedBody
+    }
+
+    for _, comment := range post.TopComments {
+        translated, err := a.translator.Translate(ctx, comment.Body, lang)
+        if err != nil {
+            log.Printf("Failed to translate comment on post %s into %s: %v", post.RedditID, lang, err)
+        }
+        t.Comments = append(t.Comments, translated)
+    }
+
+    return t, !t.Empty()
+}
+
+// SourceFailures returns how many runs in a row each failing source has failed
+func (a *App) SourceFailures() map[string]int {
+    return a.failures.Snapshot()
+}
+
+// fetchPosts collects posts from every source. Failing sources are logged
+// and skipped; it only returns an error when no source succeeded. Results
+// are nil when the scraper doesn't report them per source.
+func (a *App) fetchPosts(ctx context.Context) ([]storage.Post, []scraper.SourceResult, error) {
+    fetcher, ok := a.scraper.(scraper.ResultFetcher)
+    if !ok {
+        posts, err := a.scraper.FetchPosts(ctx)
+        return posts, nil, err
+    }
+
+    results := fetcher.FetchResults(ctx)
+    a.failures.Record(results)
+
+    var posts []storage.Post
+    failed, belowThreshold := 0, 0
+    for _, result := range results {
+        if result.Err != nil {
+            failed++
+            log.Printf("Source %s failed (%d runs in a row): %v", result.Source, a.failures.Failures(result.Source), result.Err)
+            continue
+        }
+
+        if result.NotModified {
+            log.Printf("Source %s: not modified since last run", result.Source)
+            continue
+        }
+
+        log.Printf("Source %s: %d posts, %d below upvote threshold, %d filtered", result.Source, len(result.Posts), result.BelowThreshold, result.Filtered)
+        belowThreshold += result.BelowThreshold
+        posts = append(posts, result.Posts...)
+    }
+
+    for _, source := range a.failures.Failing(a.failureAlertAfter) {
+        log.Printf("ALERT: source %s has failed %d runs in a row", source, a.failures.Failures(source))
+    }
+
+    a.checkHealth(ctx, results)
+
+    if len(results) > 0 && failed == len(results) {
+        return nil, nil, fmt.Errorf("all %d sources failed", failed)
+    }
+
+    log.Printf("%d of %d sources succeeded, %d posts below upvote threshold", len(results)-failed, len(results), belowThreshold)
+    return posts, results, nil
+}
+
+// commitCaches lets sources answer 304 next run, except those with posts in
+// unsaved: posts cut by ranking or failed translation come back only if the
+// source is downloaded in full again.
+func commitCaches(ctx context.Context, results []scraper.SourceResult, unsaved map[string]bool) {
+    for _, result := range results {
+        if result.Err != nil || result.NotModified || hasUnsaved(result.Posts, unsaved) {
+            continue
+        }
+        result.CommitCache(ctx)
+    }
+}
+
+func hasUnsaved(posts []storage.Post, unsaved map[string]bool) bool {
+    for _, post := range posts {
+        if unsaved[post.RedditID] {
+            return true
+        }
+    }
+    return false
+}
+
+// checkHealth alerts about sources that answer but no longer yield posts,
+// which usually means their markup changed
+func (a *App) checkHealth(ctx context.Context, results []scraper.SourceResult) {
+    if a.health == nil {
+        return
+    }
+
+    degraded, err := a.health.Check(ctx, results)
+    if err != nil {
+        log.Printf("Failed to check source health: %v", err)
+    }
+
+    for _, d := range degraded {
+        log.Printf("ALERT: source %s is degraded: %s", d.Source, d.Reason)
+        if a.alerter == nil {
+            continue
+        }
+        text := fmt.Sprintf("Source %s is degraded: %s", d.Source, d.Reason)
+        if err := a.alerter.SendAlert(ctx, text); err != nil {
+            log.Printf("Failed to send alert for %s: %v", d.Source, err)
+        }
+    }
+}
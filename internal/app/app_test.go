@@ -0,0 +1,405 @@
+package app
+
+import (
+    "context"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "sync/atomic"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "github.com/w1zzzle/ai-newsbot/internal/dedup"
+    "github.com/w1zzzle/ai-newsbot/internal/ranking"
+    "github.com/w1zzzle/ai-newsbot/internal/rules"
+    "github.com/w1zzzle/ai-newsbot/internal/scraper"
+    "github.com/w1zzzle/ai-newsbot/internal/storage"
+)
+
+// fakeStore keeps posts in memory and implements the duplicate lookups of
+// dedup.Store by canonical URL
+type fakeStore struct {
+    posts      map[string]storage.Post
+    published  map[string]bool // keyed by reddit ID and language
+    marked     []string
+    duplicates map[string]string
+}
+
+func newFakeStore() *fakeStore {
+    return &fakeStore{
+        posts:      make(map[string]storage.Post),
+        published:  make(map[string]bool),
+        duplicates: make(map[string]string),
+    }
+}
+
+func (s *fakeStore) SavePost(ctx context.Context, p storage.Post) error {
+    s.posts[p.RedditID] = p
+    return nil
+}
+
+func (s *fakeStore) IsPostSeen(ctx context.Context, redditID string) (bool, error) {
+    _, ok := s.posts[redditID]
+    return ok, nil
+}
+
+func (s *fakeStore) ListUnpublishedPosts(ctx context.Context, lang string) ([]storage.Post, error) {
+    var unpublished []storage.Post
+    for _, post := range s.posts {
+        if t, ok := post.Translations[lang]; ok && !t.Empty() && !s.published[post.RedditID+"/"+lang] {
+            post.UseTranslation(lang)
+            unpublished = append(unpublished, post)
+        }
+    }
+    return unpublished, nil
+}
+
+func (s *fakeStore) MarkPublished(ctx context.Context, redditID, lang string) error {
+    s.published[redditID+"/"+lang] = true
+    s.marked = append(s.marked, redditID+"/"+lang)
+    return nil
+}
+
+func (s *fakeStore) Close() error {
+    return nil
+}
+
+func (s *fakeStore) FindDuplicate(ctx context.Context, p storage.Post, maxDistance int) (string, bool, error) {
+    for id, saved := range s.posts {
+        if p.CanonicalURL != "" && saved.CanonicalURL == p.CanonicalURL {
+            return id, true, nil
+        }
+    }
+    return "", false, nil
+}
+
+func (s *fakeStore) SaveDuplicate(ctx context.Context, p storage.Post, originalID string) error {
+    s.duplicates[p.RedditID] = originalID
+    return nil
+}
+
+type fakeScraper struct {
+    posts []storage.Post
+}
+
+func (s *fakeScraper) FetchPosts(ctx context.Context) ([]storage.Post, error) {
+    return s.posts, nil
+}
+
+// fakeResultScraper reports per-source results, like the real scrapers
+type fakeResultScraper struct {
+    fakeScraper
+    results []scraper.SourceResult
+}
+
+func (s *fakeResultScraper) FetchResults(ctx context.Context) []scraper.SourceResult {
+    return s.results
+}
+
+// fakeTranslator prefixes texts with their language and fails the
+// languages in fail
+type fakeTranslator struct {
+    fail  map[string]bool
+    calls map[string]int
+}
+
+func newFakeTranslator(fail ...string) *fakeTranslator {
+    t := &fakeTranslator{
+        fail:  make(map[string]bool),
+        calls: make(map[string]int),
+    }
+    for _, lang := range fail {
+        t.fail[lang] = true
+    }
+    return t
+}
+
+func (t *fakeTranslator) Translate(ctx context.Context, text, targetLang string) (string, error) {
+    t.calls[targetLang]++
+    if t.fail[targetLang] {
+        return "", fmt.Errorf("translation into %s failed", targetLang)
+    }
+    return "[" + targetLang + "] " + text, nil
+}
+
+func (t *fakeTranslator) TranslateBatch(ctx context.Context, texts []string, targetLang string) ([]string, error) {
+    results := make([]string, len(texts))
+    for i, text := range texts {
+        translated, err := t.Translate(ctx, text, targetLang)
+        if err != nil {
+            return nil, err
+        }
+        results[i] = translated
+    }
+    return results, nil
+}
+
+func (t *fakeTranslator) IsHealthy(ctx context.Context) error {
+    return nil
+}
+
+type fakeBot struct {
+    sent []storage.Post
+    err  error
+}
+
+func (b *fakeBot) SendPost(ctx context.Context, post storage.Post) error {
+    if b.err != nil {
+        return b.err
+    }
+    b.sent = append(b.sent, post)
+    return nil
+}
+
+type fakeAlerter struct {
+    alerts []string
+}
+
+func (a *fakeAlerter) SendAlert(ctx context.Context, text string) error {
+    a.alerts = append(a.alerts, text)
+    return nil
+}
+
+// fakeRunStore returns the same history for every source
+type fakeRunStore struct {
+    history  []storage.SourceRun
+    recorded []storage.SourceRun
+}
+
+func (s *fakeRunStore) RecordSourceRun(ctx context.Context, run storage.SourceRun) error {
+    s.recorded = append(s.recorded, run)
+    return nil
+}
+
+func (s *fakeRunStore) RecentSourceRuns(ctx context.Context, source string, n int) ([]storage.SourceRun, error) {
+    return s.history, nil
+}
+
+func testPost(id, title string) storage.Post {
+    return storage.Post{
+        RedditID:  id,
+        Title:     title,
+        Body:      "Body of " + title,
+        CreatedAt: time.Now().Add(-time.Hour),
+    }
+}
+
+func savedIDs(store *fakeStore) []string {
+    var ids []string
+    for id := range store.posts {
+        ids = append(ids, id)
+    }
+    return ids
+}
+
+func TestApp_RunPipeline_PublishesEveryLanguage(t *testing.T) {
+    store := newFakeStore()
+    translator := newFakeTranslator()
+    ru, en, de := &fakeBot{}, &fakeBot{}, &fakeBot{}
+
+    a := New(store, &fakeScraper{posts: []storage.Post{testPost("p1", "New model released")}}, translator, ru,
+        WithLanguages("ru", "en", "de"),
+        WithChannel("en", en),
+        WithChannel("de", de),
+    )
+    require.NoError(t, a.RunPipeline(context.Background()))
+
+    // One translation per language
+    assert.Equal(t, map[string]int{"ru": 1, "en": 1, "de": 1}, translator.calls)
+
+    for lang, channel := range map[string]*fakeBot{"ru": ru, "en": en, "de": de} {
+        require.Len(t, channel.sent, 1, lang)
+        assert.Equal(t, "["+lang+"] Body of New model released", channel.sent[0].TranslatedBody)
+        assert.Equal(t, lang, channel.sent[0].Lang)
+    }
+    assert.ElementsMatch(t, []string{"p1/ru", "p1/en", "p1/de"}, store.marked)
+}
+
+func TestApp_RunPipeline_FailedTranslationKeepsOtherLanguages(t *testing.T) {
+    store := newFakeStore()
+    ru, en := &fakeBot{}, &fakeBot{}
+
+    a := New(store, &fakeScraper{posts: []storage.Post{testPost("p1", "New model released")}}, newFakeTranslator("en"), ru,
+        WithLanguages("ru", "en"),
+        WithChannel("en", en),
+    )
+    require.NoError(t, a.RunPipeline(context.Background()))
+
+    assert.Len(t, ru.sent, 1)
+    assert.Empty(t, en.sent)
+    assert.Equal(t, []string{"p1/ru"}, store.marked)
+    assert.NotContains(t, store.posts["p1"].Translations, "en")
+}
+
+func TestApp_RunPipeline_FailedSendKeepsOtherLanguages(t *testing.T) {
+    store := newFakeStore()
+    ru, en := &fakeBot{err: fmt.Errorf("chat not found")}, &fakeBot{}
+
+    a := New(store, &fakeScraper{posts: []storage.Post{testPost("p1", "New model released")}}, newFakeTranslator(), ru,
+        WithLanguages("ru", "en"),
+        WithChannel("en", en),
+    )
+    require.NoError(t, a.RunPipeline(context.Background()))
+
+    // The post stays unpublished in Russian and is retried next run
+    assert.Len(t, en.sent, 1)
+    assert.Equal(t, []string{"p1/en"}, store.marked)
+}
+
+func TestApp_RunPipeline_RanksCandidates(t *testing.T) {
+    slow := testPost("slow", "Slow post")
+    slow.Score = 10
+    fast := testPost("fast", "Fast post")
+    fast.Score = 1000
+
+    store := newFakeStore()
+    channel := &fakeBot{}
+    a := New(store, &fakeScraper{posts: []storage.Post{slow, fast}}, newFakeTranslator(), channel,
+        WithRanker(ranking.New(ranking.Weights{Score: 1, Comments: 2}, 1)),
+    )
+    require.NoError(t, a.RunPipeline(context.Background()))
+
+    assert.Equal(t, []string{"fast"}, savedIDs(store))
+    require.Len(t, channel.sent, 1)
+    assert.Equal(t, "fast", channel.sent[0].RedditID)
+}
+
+func TestApp_RunPipeline_AppliesRules(t *testing.T) {
+    engine, err := rules.New(rules.Rule{Keywords: []string{"llama"}})
+    require.NoError(t, err)
+
+    store := newFakeStore()
+    posts := []storage.Post{testPost("p1", "Llama 4 released"), testPost("p2", "Weekly meme thread")}
+    a := New(store, &fakeScraper{posts: posts}, newFakeTranslator(), &fakeBot{}, WithRules(engine))
+    require.NoError(t, a.RunPipeline(context.Background()))
+
+    assert.Equal(t, []string{"p1"}, savedIDs(store))
+}
+
+func TestApp_RunPipeline_DropsDuplicates(t *testing.T) {
+    store := newFakeStore()
+    store.posts["old"] = storage.Post{RedditID: "old", CanonicalURL: dedup.CanonicalURL("https://example.com/earlier")}
+
+    first := testPost("p1", "Model weights released")
+    first.URL = "https://example.com/weights"
+    copied := testPost("p2", "Lab publishes its model weights")
+    copied.URL = "https://example.com/weights?utm_source=reddit"
+    copied.CreatedAt = first.CreatedAt.Add(time.Minute)
+    earlier := testPost("p3", "Earlier story again")
+    earlier.URL = "https://example.com/earlier"
+
+    a := New(store, &fakeScraper{posts: []storage.Post{first, copied, earlier}}, newFakeTranslator(), &fakeBot{},
+        WithDuplicateDetector(dedup.NewDetector(store, nil)),
+    )
+    require.NoError(t, a.RunPipeline(context.Background()))
+
+    assert.ElementsMatch(t, []string{"old", "p1"}, savedIDs(store))
+    // Copies of stories saved in earlier runs are merged into them
+    assert.Equal(t, map[string]string{"p3": "old"}, store.duplicates)
+}
+
+func TestApp_RunPipeline_AlertsDegradedSources(t *testing.T) {
+    runs := &fakeRunStore{history: []storage.SourceRun{{Posts: 12}, {Posts: 9}}}
+    monitor := scraper.NewHealthMonitor(runs)
+    monitor.History = 2
+
+    source := &fakeResultScraper{}
+    source.results = []scraper.SourceResult{
+        {Source: "https://example.com/news", Posts: nil},
+        {Source: "https://example.com/blog", Posts: []storage.Post{testPost("p1", "Still working")}},
+    }
+
+    alerter := &fakeAlerter{}
+    a := New(newFakeStore(), source, newFakeTranslator(), &fakeBot{}, WithHealthMonitor(monitor, alerter))
+    require.NoError(t, a.RunPipeline(context.Background()))
+
+    require.Len(t, alerter.alerts, 1)
+    assert.Contains(t, alerter.alerts[0], "https://example.com/news")
+    assert.Len(t, runs.recorded, 2)
+
+    // A source that stays degraded is only reported once
+    require.NoError(t, a.RunPipeline(context.Background()))
+    assert.Len(t, alerter.alerts, 1)
+}
+
+func TestApp_RunPipeline_SkipsOverlappingRun(t *testing.T) {
+    store := newFakeStore()
+    a := New(store, &fakeScraper{posts: []storage.Post{testPost("p1", "New model released")}}, newFakeTranslator(), &fakeBot{})
+
+    // A run still in progress holds the lock
+    a.running.Lock()
+    require.NoError(t, a.RunPipeline(context.Background()))
+    assert.Empty(t, store.posts)
+
+    a.running.Unlock()
+    require.NoError(t, a.RunPipeline(context.Background()))
+    assert.Len(t, store.posts, 1)
+}
+
+// listingServer serves a Reddit listing of posts with an ETag and answers
+// 304 to requests that send it back. full counts full responses.
+func listingServer(t *testing.T, full *int32, ids ...string) *httptest.Server {
+    t.Helper()
+    var children []string
+    for i, id := range ids {
+        children = append(children, fmt.Sprintf(`{"kind": "t3", "data": {"id": %q, "title": "Post %s", "selftext": "Body", "score": %d, "is_self": true, "created_utc": %d}}`,
+            id, id, 100*(len(ids)-i), time.Now().Add(-time.Hour).Unix()))
+    }
+    listing := `{"kind": "Listing", "data": {"children": [` + strings.Join(children, ", ") + `]}}`
+
+    return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if r.Header.Get("If-None-Match") == `"v1"` {
+            w.WriteHeader(http.StatusNotModified)
+            return
+        }
+        atomic.AddInt32(full, 1)
+        w.Header().Set("ETag", `"v1"`)
+        w.Write([]byte(listing))
+    }))
+}
+
+func TestApp_RunPipeline_RefetchesSourcesWithUnsavedPosts(t *testing.T) {
+    var full int32
+    server := listingServer(t, &full, "a1", "a2")
+    defer server.Close()
+
+    store := newFakeStore()
+    postScraper := scraper.NewJSON([]string{server.URL + "/r/ml/top/"}, 0)
+    a := New(store, postScraper, newFakeTranslator(), &fakeBot{},
+        WithRanker(ranking.New(ranking.Weights{Score: 1}, 1)),
+    )
+
+    // The post cut by ranking keeps the listing from being cached
+    require.NoError(t, a.RunPipeline(context.Background()))
+    assert.Equal(t, []string{"a1"}, savedIDs(store))
+
+    require.NoError(t, a.RunPipeline(context.Background()))
+    assert.ElementsMatch(t, []string{"a1", "a2"}, savedIDs(store))
+    assert.Equal(t, int32(2), atomic.LoadInt32(&full))
+
+    // With every post saved, the next run gets a 304
+    require.NoError(t, a.RunPipeline(context.Background()))
+    assert.Equal(t, int32(2), atomic.LoadInt32(&full))
+}
+
+func TestApp_RunPipeline_RefetchesSourcesWithFailedTranslations(t *testing.T) {
+    var full int32
+    server := listingServer(t, &full, "a1")
+    defer server.Close()
+
+    store := newFakeStore()
+    translator := newFakeTranslator("ru")
+    a := New(store, scraper.NewJSON([]string{server.URL + "/r/ml/top/"}, 0), translator, &fakeBot{})
+
+    require.NoError(t, a.RunPipeline(context.Background()))
+    assert.Empty(t, store.posts)
+
+    // The post comes back once translation works again
+    translator.fail = map[string]bool{}
+    require.NoError(t, a.RunPipeline(context.Background()))
+    assert.Equal(t, []string{"a1"}, savedIDs(store))
+    assert.Equal(t, int32(2), atomic.LoadInt32(&full))
+}
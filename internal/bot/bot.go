@@ -4,6 +4,7 @@ import (
     "context"
     "fmt"
     "strings"
+    "unicode/utf8"
 
     tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
     "github.com/w1zzzle/ai-newsbot/internal/storage"
@@ -13,34 +14,86 @@ type Bot interface {
     SendPost(ctx context.Context, post storage.Post) error
 }
 
+// Alerter notifies the bot's operators about problems with the pipeline
+type Alerter interface {
+    SendAlert(ctx context.Context, text string) error
+}
+
 type TelegramBot struct {
-    api    *tgbotapi.BotAPI
-    chatID int64
+    api         *tgbotapi.BotAPI
+    chatID      int64
+    adminChatID int64
 }
 
-func New(token string, chatID int64) (*TelegramBot, error) {
+// Option configures a TelegramBot
+type Option func(*TelegramBot)
+
+// WithAdminChat sends alerts to the given chat instead of the channel
+func WithAdminChat(chatID int64) Option {
+    return func(b *TelegramBot) {
+        b.adminChatID = chatID
+    }
+}
+
+func New(token string, chatID int64, opts ...Option) (*TelegramBot, error) {
     bot, err := tgbotapi.NewBotAPI(token)
     if err != nil {
         return nil, fmt.Errorf("failed to create telegram bot: %w", err)
     }
 
-    return &TelegramBot{
+    b := &TelegramBot{
         api:    bot,
         chatID: chatID,
-    }, nil
+    }
+    for _, opt := range opts {
+        opt(b)
+    }
+    return b, nil
 }
 
-func (b *TelegramBot) SendPost(ctx context.Context, post storage.Post) error {
-    // Prepare message text
-    messageText := b.formatMessage(post)
+// ForChat returns a bot that publishes to chatID through the same API
+// connection, such as the channel of another language
+func (b *TelegramBot) ForChat(chatID int64) *TelegramBot {
+    channel := *b
+    channel.chatID = chatID
+    return &channel
+}
 
-    // Send media if available
-    if len(post.MediaURLs) > 0 {
-        return b.sendMediaWithCaption(ctx, post.MediaURLs[0], messageText)
+// SendAlert sends a plain-text alert to the admin chat. Without an admin
+// chat alerts are dropped, so they never reach subscribers.
+func (b *TelegramBot) SendAlert(ctx context.Context, text string) error {
+    if b.adminChatID == 0 {
+        return nil
     }
 
-    // Send text message
-    return b.sendTextMessage(ctx, messageText)
+    msg := tgbotapi.NewMessage(b.adminChatID, "⚠️ "+text)
+    _, err := b.api.Send(msg)
+    return err
+}
+
+func (b *TelegramBot) SendPost(ctx context.Context, post storage.Post) error {
+    if len(post.Media) == 0 && len(post.MediaURLs) == 0 {
+        return b.sendTextMessage(ctx, b.formatMessage(post))
+    }
+
+    // Captions are short, so comments that don't fit follow the media
+    caption, comments := b.formatCaption(post)
+
+    var err error
+    switch album := albumMedia(post.Media); {
+    case len(album) > 1:
+        // Several photos or videos go out as one album
+        err = b.sendAlbum(ctx, album, caption)
+    case len(post.Media) > 0:
+        // Typed media picks its send method; bare URLs are guessed from the extension
+        err = b.sendTypedMedia(ctx, post.Media[0], caption)
+    default:
+        err = b.sendMediaWithCaption(ctx, post.MediaURLs[0], caption)
+    }
+    if err != nil || comments == "" {
+        return err
+    }
+    return b.sendTextMessage(ctx, comments)
 }
 
 func (b *TelegramBot) formatMessage(post storage.Post) string {
@@ -56,9 +109,103 @@ func (b *TelegramBot) formatMessage(post storage.Post) string {
         message.WriteString(b.escapeMarkdown(post.TranslatedBody))
     }
 
+    if comments := b.formatComments(post.TopComments, post.Lang); comments != "" {
+        if post.TranslatedBody != "" {
+            message.WriteString("\n\n")
+        }
+        message.WriteString(comments)
+    }
+
     return message.String()
 }
 
+// maxCaptionLength is the most characters Telegram accepts in a media
+// caption
+const maxCaptionLength = 1024
+
+// formatCaption formats a post as a media caption. When the message is too
+// long for one, the comments are returned separately to follow the media,
+// and a body that still doesn't fit is shortened.
+func (b *TelegramBot) formatCaption(post storage.Post) (caption, comments string) {
+    caption = b.formatMessage(post)
+    if utf8.RuneCountInString(caption) <= maxCaptionLength {
+        return caption, ""
+    }
+
+    comments = b.formatComments(post.TopComments, post.Lang)
+    post.TopComments = nil
+
+    body := []rune(post.TranslatedBody)
+    for {
+        caption = b.formatMessage(post)
+        excess := utf8.RuneCountInString(caption) - maxCaptionLength
+        if excess <= 0 || len(body) == 0 {
+            return caption, comments
+        }
+        // One more rune makes room for the ellipsis
+        body = body[:max(len(body)-excess-1, 0)]
+        post.TranslatedBody = strings.TrimSpace(string(body)) + "…"
+    }
+}
+
+// maxCommentLength keeps quoted comments short
+const maxCommentLength = 280
+
+// commentsHeadings introduce the quotes, keyed by language code
+var commentsHeadings = map[string]string{
+    "ru": "Что говорит сообщество",
+    "uk": "Що каже спільнота",
+    "en": "What the community says",
+    "de": "Was die Community sagt",
+    "fr": "Ce qu'en dit la communauté",
+    "es": "Lo que dice la comunidad",
+    "it": "Cosa dice la community",
+    "pt": "O que a comunidade diz",
+}
+
+// commentsHeading returns the heading of the quotes in lang. Languages
+// without one get the emoji alone rather than a heading they can't read.
+func commentsHeading(lang string) string {
+    if heading, ok := commentsHeadings[lang]; ok {
+        return "💬 *" + heading + "*"
+    }
+    return "💬"
+}
+
+// formatComments renders the translated top comments as a quote section
+// under a heading in lang
+func (b *TelegramBot) formatComments(comments []storage.Comment, lang string) string {
+    var section strings.Builder
+
+    for _, comment := range comments {
+        if comment.TranslatedBody == "" {
+            continue
+        }
+        if section.Len() == 0 {
+            section.WriteString(commentsHeading(lang) + "\n")
+        }
+        section.WriteString("• ")
+        section.WriteString(b.escapeMarkdown(truncate(comment.TranslatedBody, maxCommentLength)))
+        if comment.Author != "" {
+            section.WriteString(" — u/")
+            section.WriteString(b.escapeMarkdown(comment.Author))
+        }
+        section.WriteString("\n")
+    }
+
+    return strings.TrimSuffix(section.String(), "\n")
+}
+
+// truncate shortens text to at most n runes, ending with an ellipsis
+func truncate(text string, n int) string {
+    text = strings.Join(strings.Fields(text), " ")
+    runes := []rune(text)
+    if len(runes) <= n {
+        return text
+    }
+    return strings.TrimSpace(string(runes[:n-1])) + "…"
+}
+
 func (b *TelegramBot) sendTextMessage(ctx context.Context, text string) error {
     msg := tgbotapi.NewMessage(b.chatID, text)
     msg.ParseMode = tgbotapi.ModeMarkdown
@@ -81,6 +228,107 @@ func (b *TelegramBot) sendMediaWithCaption(ctx context.Context, mediaURL, captio
     return b.sendTextMessage(ctx, caption)
 }
 
+// Telegram send methods for typed media
+const (
+    methodPhoto     = "photo"
+    methodDocument  = "document"
+    methodAnimation = "animation"
+    methodVideo     = "video"
+)
+
+// Telegram rejects photos whose width and height add up to more than 10000
+// pixels or whose aspect ratio exceeds 20, and albums of more than 10 items
+const (
+    maxPhotoSides  = 10000
+    maxPhotoAspect = 20
+    maxAlbumSize   = 10
+)
+
+// sendMethod chooses how Telegram should receive a media item
+func sendMethod(media storage.Media) string {
+    switch media.Type {
+    case storage.MediaGIF:
+        return methodAnimation
+    case storage.MediaVideo:
+        return methodVideo
+    }
+
+    width, height := media.Width, media.Height
+    if width+height > maxPhotoSides {
+        return methodDocument
+    }
+    if width > 0 && height > 0 && (width > maxPhotoAspect*height || height > maxPhotoAspect*width) {
+        return methodDocument
+    }
+    return methodPhoto
+}
+
+// albumMedia returns the items that can share an album: photos and videos,
+// at most maxAlbumSize of them
+func albumMedia(media []storage.Media) []storage.Media {
+    var album []storage.Media
+    for _, m := range media {
+        if method := sendMethod(m); method != methodPhoto && method != methodVideo {
+            continue
+        }
+        album = append(album, m)
+        if len(album) == maxAlbumSize {
+            break
+        }
+    }
+    return album
+}
+
+func (b *TelegramBot) sendTypedMedia(ctx context.Context, media storage.Media, caption string) error {
+    switch sendMethod(media) {
+    case methodAnimation:
+        return b.sendAnimation(ctx, media.URL, caption)
+    case methodVideo:
+        return b.sendVideo(ctx, media.URL, caption)
+    case methodDocument:
+        return b.sendDocument(ctx, media.URL, caption)
+    default:
+        return b.sendPhoto(ctx, media.URL, caption)
+    }
+}
+
+// sendAlbum sends media as one album, captioned on its first item
+func (b *TelegramBot) sendAlbum(ctx context.Context, media []storage.Media, caption string) error {
+    items := make([]interface{}, 0, len(media))
+    for i, m := range media {
+        if sendMethod(m) == methodVideo {
+            video := tgbotapi.NewInputMediaVideo(tgbotapi.FileURL(m.URL))
+            video.Width, video.Height = m.Width, m.Height
+            video.SupportsStreaming = true
+            if i == 0 {
+                video.Caption = caption
+                video.ParseMode = tgbotapi.ModeMarkdown
+            }
+            items = append(items, video)
+            continue
+        }
+
+        photo := tgbotapi.NewInputMediaPhoto(tgbotapi.FileURL(m.URL))
+        if i == 0 {
+            photo.Caption = caption
+            photo.ParseMode = tgbotapi.ModeMarkdown
+        }
+        items = append(items, photo)
+    }
+
+    _, err := b.api.SendMediaGroup(tgbotapi.NewMediaGroup(b.chatID, items))
+    return err
+}
+
+func (b *TelegramBot) sendDocument(ctx context.Context, documentURL, caption string) error {
+    msg := tgbotapi.NewDocument(b.chatID, tgbotapi.FileURL(documentURL))
+    msg.Caption = caption
+    msg.ParseMode = tgbotapi.ModeMarkdown
+
+    _, err := b.api.Send(msg)
+    return err
+}
+
 func (b *TelegramBot) sendPhoto(ctx context.Context, photoURL, caption string) error {
     msg := tgbotapi.NewPhoto(b.chatID, tgbotapi.FileURL(photoURL))
     msg.Caption = caption
@@ -93,6 +341,7 @@ func (b *TelegramBot) sendPhoto(ctx context.Context, photoURL, caption string) e
 func (b *TelegramBot) sendVideo(ctx context.Context, videoURL, caption string) error {
     msg := tgbotapi.NewVideo(b.chatID, tgbotapi.FileURL(videoURL))
     msg.Caption = caption
+    msg.SupportsStreaming = true
     msg.ParseMode = tgbotapi.ModeMarkdown
 
     _, err := b.api.Send(msg)
@@ -2,6 +2,7 @@ package bot
 
 import (
     "context"
+    "strings"
     "testing"
     "time"
 
@@ -23,6 +24,72 @@ func TestTelegramBot_FormatMessage(t *testing.T) {
     assert.Contains(t, message, "Исследователи достигли нового прорыва в машинном обучении.")
 }
 
+func TestTelegramBot_FormatMessageWithComments(t *testing.T) {
+    bot := &TelegramBot{chatID: 123}
+
+    post := storage.Post{
+        Title: "Open 7B model matches 70B",
+        Lang:  "ru",
+        TopComments: []storage.Comment{
+            {Author: "gpu_poor", Body: "Finally something I can run.", Score: 845, TranslatedBody: "Наконец-то что-то, что я могу запустить."},
+            {Author: "skeptic", Body: "Not translated", Score: 210},
+            {Author: "long_winded", Body: "...", Score: 100, TranslatedBody: strings.Repeat("слово ", 100)},
+        },
+    }
+
+    message := bot.formatMessage(post)
+
+    // Link posts without a body still get the comment section
+    assert.Contains(t, message, "📰 *Open 7B model matches 70B*\n\n💬 *Что говорит сообщество*\n")
+    assert.Contains(t, message, "• Наконец-то что-то, что я могу запустить. — u/gpu\\_poor")
+    assert.NotContains(t, message, "skeptic")
+    assert.Contains(t, message, "…")
+    assert.Less(t, len([]rune(message)), 450)
+}
+
+func TestTelegramBot_FormatComments_Heading(t *testing.T) {
+    bot := &TelegramBot{chatID: 123}
+    comments := []storage.Comment{{Author: "gpu_poor", TranslatedBody: "Finally something I can run."}}
+
+    assert.True(t, strings.HasPrefix(bot.formatComments(comments, "en"), "💬 *What the community says*\n• "))
+    assert.True(t, strings.HasPrefix(bot.formatComments(comments, "de"), "💬 *Was die Community sagt*\n• "))
+
+    // A language without a heading gets none in another language
+    assert.True(t, strings.HasPrefix(bot.formatComments(comments, "ja"), "💬\n• "))
+}
+
+func TestTelegramBot_FormatCaption(t *testing.T) {
+    bot := &TelegramBot{chatID: 123}
+
+    post := storage.Post{
+        Title:          "Open 7B model matches 70B",
+        TranslatedBody: "Короткий пост.",
+        Lang:           "ru",
+        TopComments: []storage.Comment{
+            {Author: "gpu_poor", TranslatedBody: "Наконец-то что-то, что я могу запустить."},
+        },
+    }
+
+    // A short message fits in one caption
+    caption, comments := bot.formatCaption(post)
+    assert.Equal(t, bot.formatMessage(post), caption)
+    assert.Empty(t, comments)
+
+    // Comments that don't fit follow the media, heading included
+    for i := 0; i < 5; i++ {
+        post.TopComments = append(post.TopComments, storage.Comment{TranslatedBody: strings.Repeat("слово ", 40)})
+    }
+    caption, comments = bot.formatCaption(post)
+    assert.Equal(t, "📰 *Open 7B model matches 70B*\n\nКороткий пост.", caption)
+    assert.True(t, strings.HasPrefix(comments, "💬 *Что говорит сообщество*\n• Наконец-то"))
+
+    // A body too long on its own is shortened
+    post.TranslatedBody = strings.Repeat("очень_длинный ", 100)
+    caption, _ = bot.formatCaption(post)
+    assert.LessOrEqual(t, len([]rune(caption)), maxCaptionLength)
+    assert.True(t, strings.HasSuffix(caption, "…"))
+}
+
 func TestTelegramBot_EscapeMarkdown(t *testing.T) {
     bot := &TelegramBot{}
 
@@ -43,6 +110,45 @@ func TestTelegramBot_EscapeMarkdown(t *testing.T) {
     }
 }
 
+func TestSendMethod(t *testing.T) {
+    tests := []struct {
+        name     string
+        media    storage.Media
+        expected string
+    }{
+        {"photo", storage.Media{Type: storage.MediaImage, Width: 1200, Height: 800}, methodPhoto},
+        {"photo without dimensions", storage.Media{Type: storage.MediaImage}, methodPhoto},
+        {"oversized photo", storage.Media{Type: storage.MediaImage, Width: 8000, Height: 6000}, methodDocument},
+        {"panorama", storage.Media{Type: storage.MediaImage, Width: 4200, Height: 200}, methodDocument},
+        {"gif", storage.Media{Type: storage.MediaGIF, Width: 480, Height: 480}, methodAnimation},
+        {"video", storage.Media{Type: storage.MediaVideo, Width: 1920, Height: 1080}, methodVideo},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            assert.Equal(t, tt.expected, sendMethod(tt.media))
+        })
+    }
+}
+
+func TestAlbumMedia(t *testing.T) {
+    media := []storage.Media{
+        {URL: "https://i.redd.it/a.png", Type: storage.MediaImage},
+        {URL: "https://i.redd.it/b.gif", Type: storage.MediaGIF},
+        {URL: "https://v.redd.it/c/DASH_720.mp4", Type: storage.MediaVideo},
+    }
+
+    album := albumMedia(media)
+    assert.Len(t, album, 2)
+    assert.Equal(t, "https://i.redd.it/a.png", album[0].URL)
+    assert.Equal(t, "https://v.redd.it/c/DASH_720.mp4", album[1].URL)
+
+    for i := 0; i < 12; i++ {
+        media = append(media, storage.Media{URL: "https://i.redd.it/x.png", Type: storage.MediaImage})
+    }
+    assert.Len(t, albumMedia(media), maxAlbumSize)
+}
+
 func TestTelegramBot_IsImageURL(t *testing.T) {
     bot := &TelegramBot{}
 
@@ -118,4 +224,22 @@ func TestMockBot(t *testing.T) {
     assert.NoError(t, err)
     assert.Len(t, bot.SentPosts, 1)
     assert.Equal(t, "test123", bot.SentPosts[0].RedditID)
-}
\ No newline at end of file
+}
+
+func TestTelegramBot_SendAlertWithoutAdminChat(t *testing.T) {
+    bot := &TelegramBot{chatID: 123}
+
+    // Alerts must never fall through to the public channel
+    err := bot.SendAlert(context.Background(), "source degraded")
+    assert.NoError(t, err)
+}
+
+func TestTelegramBot_ForChat(t *testing.T) {
+    bot := &TelegramBot{chatID: 123, adminChatID: 999}
+
+    channel := bot.ForChat(456)
+    assert.Equal(t, int64(456), channel.chatID)
+    assert.Equal(t, int64(999), channel.adminChatID)
+    // The original bot keeps its channel
+    assert.Equal(t, int64(123), bot.chatID)
+}
@@ -3,17 +3,53 @@ package config
 import (
     "fmt"
     "os"
+    "slices"
     "strconv"
     "strings"
 )
 
 type Config struct {
-    RedditURLs        []string
-    UpvoteThreshold   int
-    PostgresDSN       string
-    OpenRouterAPIKey  string
-    TelegramBotToken  string
-    TelegramChatID    int64
+    RedditURLs                []string
+    UpvoteThreshold           int
+    Sources                   []Source
+    RulesFile                 string
+    RankScoreWeight           float64
+    RankCommentWeight         float64
+    RankTopN                  int
+    TopComments               int
+    FollowLinks               bool
+    SourceFailureAlertAfter   int
+    FetchWorkers              int
+    HostRequestsPerMinute     float64
+    HostBurst                 int
+    HTTPCachePostgres         bool
+    ScraperUserAgent          string
+    ScraperTimeoutSeconds     int
+    FixtureMode               string
+    FixtureDir                string
+    RedditClientID            string
+    RedditClientSecret        string
+    RedditUsername            string
+    RedditPassword            string
+    RedditUserAgent           string
+    GitHubAPIURL              string
+    GitHubToken               string
+    PostgresDSN               string
+    OpenRouterAPIKey          string
+    TranslationProvider       string
+    TranslationAPIKey         string
+    TranslationBaseURL        string
+    TranslationModel          string
+    TranslationTemperature    *float64
+    TranslationMaxTokens      int
+    TranslationTimeoutSeconds int
+    TranslationHeaders        map[string]string
+    TranslationLanguages      []string
+    TranslationSummaries      []string
+    TelegramBotToken          string
+    TelegramChatID            int64
+    TelegramChatIDs           map[string]int64
+    TelegramAdminChatID       int64
 }
 
 func Load() (*Config, error) {
@@ -38,18 +74,159 @@ func Load() (*Config, error) {
         cfg.UpvoteThreshold = threshold
     }
 
+    // Per-source settings
+    sources, err := loadSources(os.Getenv("SOURCES_FILE"), cfg.RedditURLs, cfg.UpvoteThreshold)
+    if err != nil {
+        return nil, err
+    }
+    cfg.Sources = sources
+
+    // Content rules applied after fetching, optional
+    cfg.RulesFile = os.Getenv("RULES_FILE")
+
+    // Ranking
+    cfg.RankScoreWeight, err = floatEnv("RANK_SCORE_WEIGHT", 1.0)
+    if err != nil {
+        return nil, err
+    }
+    cfg.RankCommentWeight, err = floatEnv("RANK_COMMENT_WEIGHT", 2.0)
+    if err != nil {
+        return nil, err
+    }
+    cfg.RankTopN, err = intEnv("RANK_TOP_N", 5)
+    if err != nil {
+        return nil, err
+    }
+
+    // Comments quoted under each published post, 0 disables
+    cfg.TopComments, err = intEnv("TOP_COMMENTS", 3)
+    if err != nil {
+        return nil, err
+    }
+
+    // Extract the linked article of link posts without text
+    cfg.FollowLinks, err = boolEnv("FOLLOW_LINKS", true)
+    if err != nil {
+        return nil, err
+    }
+
+    // Alert once a source has failed this many runs in a row
+    cfg.SourceFailureAlertAfter, err = intEnv("SOURCE_FAILURE_ALERT_AFTER", 3)
+    if err != nil {
+        return nil, err
+    }
+
+    // Fetching
+    cfg.FetchWorkers, err = intEnv("FETCH_WORKERS", 4)
+    if err != nil {
+        return nil, err
+    }
+    cfg.HostRequestsPerMinute, err = floatEnv("HOST_REQUESTS_PER_MINUTE", 30)
+    if err != nil {
+        return nil, err
+    }
+    cfg.HostBurst, err = intEnv("HOST_BURST", 3)
+    if err != nil {
+        return nil, err
+    }
+
+    // Keep conditional GET validators in Postgres so they survive restarts
+    cfg.HTTPCachePostgres, err = boolEnv("HTTP_CACHE_POSTGRES", false)
+    if err != nil {
+        return nil, err
+    }
+
+    // User-Agent and timeout of source requests; sources may override the agent
+    cfg.ScraperUserAgent = os.Getenv("SCRAPER_USER_AGENT")
+    cfg.ScraperTimeoutSeconds, err = intEnv("SCRAPER_TIMEOUT_SECONDS", 30)
+    if err != nil {
+        return nil, err
+    }
+
+    // Record source traffic as fixtures, or replay it offline
+    cfg.FixtureMode = os.Getenv("SCRAPER_FIXTURE_MODE")
+    switch cfg.FixtureMode {
+    case "", "record", "replay":
+    default:
+        return nil, fmt.Errorf("invalid SCRAPER_FIXTURE_MODE %q, want record or replay", cfg.FixtureMode)
+    }
+    cfg.FixtureDir = os.Getenv("SCRAPER_FIXTURE_DIR")
+    if cfg.FixtureDir == "" {
+        cfg.FixtureDir = "fixtures"
+    }
+
+    // Reddit API credentials; without a client ID listings are fetched anonymously
+    cfg.RedditClientID = os.Getenv("REDDIT_CLIENT_ID")
+    cfg.RedditClientSecret = os.Getenv("REDDIT_CLIENT_SECRET")
+    cfg.RedditUsername = os.Getenv("REDDIT_USERNAME")
+    cfg.RedditPassword = os.Getenv("REDDIT_PASSWORD")
+    cfg.RedditUserAgent = os.Getenv("REDDIT_USER_AGENT")
+    if cfg.RedditClientID != "" && cfg.RedditClientSecret == "" {
+        return nil, fmt.Errorf("REDDIT_CLIENT_SECRET is required when REDDIT_CLIENT_ID is set")
+    }
+
+    // GitHub API for release sources; a token raises the rate limit
+    cfg.GitHubAPIURL = os.Getenv("GITHUB_API_URL")
+    if cfg.GitHubAPIURL == "" {
+        cfg.GitHubAPIURL = "https://api.github.com"
+    }
+    cfg.GitHubToken = os.Getenv("GITHUB_TOKEN")
+
     // Database
     cfg.PostgresDSN = os.Getenv("POSTGRES_DSN")
     if cfg.PostgresDSN == "" {
         return nil, fmt.Errorf("POSTGRES_DSN is required")
     }
 
-    // OpenRouter API Key
+    // Translation provider; only OpenRouter, the default endpoint of the
+    // openai provider, requires OPENROUTER_API_KEY
+    cfg.TranslationProvider = os.Getenv("TRANSLATION_PROVIDER")
+    if cfg.TranslationProvider == "" {
+        cfg.TranslationProvider = "openai"
+    }
+    cfg.TranslationBaseURL = os.Getenv("TRANSLATION_BASE_URL")
     cfg.OpenRouterAPIKey = os.Getenv("OPENROUTER_API_KEY")
-    if cfg.OpenRouterAPIKey == "" {
+    cfg.TranslationAPIKey = os.Getenv("TRANSLATION_API_KEY")
+    // The OpenRouter key must never reach another provider
+    if cfg.TranslationAPIKey == "" && cfg.TranslationProvider == "openai" {
+        cfg.TranslationAPIKey = cfg.OpenRouterAPIKey
+    }
+    if cfg.TranslationProvider == "openai" && cfg.TranslationBaseURL == "" && cfg.TranslationAPIKey == "" {
         return nil, fmt.Errorf("OPENROUTER_API_KEY is required")
     }
 
+    // Translation model and parameters; unset values keep the provider's defaults
+    cfg.TranslationModel = os.Getenv("TRANSLATION_MODEL")
+    if value := os.Getenv("TRANSLATION_TEMPERATURE"); value != "" {
+        temperature, err := strconv.ParseFloat(value, 64)
+        if err != nil {
+            return nil, fmt.Errorf("invalid TRANSLATION_TEMPERATURE: %w", err)
+        }
+        cfg.TranslationTemperature = &temperature
+    }
+    cfg.TranslationMaxTokens, err = intEnv("TRANSLATION_MAX_TOKENS", 0)
+    if err != nil {
+        return nil, err
+    }
+    cfg.TranslationTimeoutSeconds, err = intEnv("TRANSLATION_TIMEOUT_SECONDS", 60)
+    if err != nil {
+        return nil, err
+    }
+    cfg.TranslationHeaders, err = headersEnv("TRANSLATION_HEADERS")
+    if err != nil {
+        return nil, err
+    }
+
+    // Every post is translated into each language, the first one being
+    // published to TELEGRAM_CHAT_ID
+    cfg.TranslationLanguages = listEnv("TRANSLATION_LANGUAGES", []string{"ru"})
+    cfg.TranslationSummaries = listEnv("TRANSLATION_SUMMARY_LANGUAGES", nil)
+    for _, lang := range cfg.TranslationSummaries {
+        if !slices.Contains(cfg.TranslationLanguages, lang) {
+            return nil, fmt.Errorf("TRANSLATION_SUMMARY_LANGUAGES has %s, which is not in TRANSLATION_LANGUAGES", lang)
+        }
+    }
+
     // Telegram Bot Token
     cfg.TelegramBotToken = os.Getenv("TELEGRAM_BOT_TOKEN")
     if cfg.TelegramBotToken == "" {
@@ -67,5 +244,111 @@ func Load() (*Config, error) {
     }
     cfg.TelegramChatID = chatID
 
+    // Channels of the other languages, as lang=chat_id pairs
+    cfg.TelegramChatIDs, err = chatIDsEnv("TELEGRAM_CHAT_IDS")
+    if err != nil {
+        return nil, err
+    }
+    for i, lang := range cfg.TranslationLanguages {
+        if _, ok := cfg.TelegramChatIDs[lang]; !ok && i > 0 {
+            return nil, fmt.Errorf("TELEGRAM_CHAT_IDS has no chat for language %s", lang)
+        }
+    }
+
+    // Telegram admin chat for operational alerts, optional
+    if adminChatIDStr := os.Getenv("TELEGRAM_ADMIN_CHAT_ID"); adminChatIDStr != "" {
+        adminChatID, err := strconv.ParseInt(adminChatIDStr, 10, 64)
+        if err != nil {
+            return nil, fmt.Errorf("invalid telegram admin chat ID: %w", err)
+        }
+        cfg.TelegramAdminChatID = adminChatID
+    }
+
     return cfg, nil
-}
\ No newline at end of file
+}
+
+func intEnv(key string, fallback int) (int, error) {
+    value := os.Getenv(key)
+    if value == "" {
+        return fallback, nil
+    }
+    n, err := strconv.Atoi(value)
+    if err != nil {
+        return 0, fmt.Errorf("invalid %s: %w", key, err)
+    }
+    return n, nil
+}
+
+func floatEnv(key string, fallback float64) (float64, error) {
+    value := os.Getenv(key)
+    if value == "" {
+        return fallback, nil
+    }
+    f, err := strconv.ParseFloat(value, 64)
+    if err != nil {
+        return 0, fmt.Errorf("invalid %s: %w", key, err)
+    }
+    return f, nil
+}
+
+func boolEnv(key string, fallback bool) (bool, error) {
+    value := os.Getenv(key)
+    if value == "" {
+        return fallback, nil
+    }
+    b, err := strconv.ParseBool(value)
+    if err != nil {
+        return false, fmt.Errorf("invalid %s: %w", key, err)
+    }
+    return b, nil
+}
+
+// headersEnv parses a comma-separated list of Name=value pairs
+func headersEnv(key string) (map[string]string, error) {
+    value := os.Getenv(key)
+    if value == "" {
+        return nil, nil
+    }
+    headers := make(map[string]string)
+    for _, pair := range strings.Split(value, ",") {
+        name, val, ok := strings.Cut(pair, "=")
+        name = strings.TrimSpace(name)
+        if !ok || name == "" {
+            return nil, fmt.Errorf("invalid %s: want Name=value pairs, got %q", key, pair)
+        }
+        headers[name] = strings.TrimSpace(val)
+    }
+    return headers, nil
+}
+
+// listEnv parses a comma-separated list, dropping empty entries
+func listEnv(key string, fallback []string) []string {
+    value := os.Getenv(key)
+    if value == "" {
+        return fallback
+    }
+    var items []string
+    for _, item := range strings.Split(value, ",") {
+        if item = strings.TrimSpace(item); item != "" {
+            items = append(items, item)
+        }
+    }
+    return items
+}
+
+// chatIDsEnv parses a comma-separated list of lang=chat_id pairs
+func chatIDsEnv(key string) (map[string]int64, error) {
+    chats := make(map[string]int64)
+    for _, pair := range listEnv(key, nil) {
+        lang, idStr, ok := strings.Cut(pair, "=")
+        if !ok {
+            return nil, fmt.Errorf("invalid %s: want lang=chat_id pairs, got %q", key, pair)
+        }
+        id, err := strconv.ParseInt(strings.TrimSpace(idStr), 10, 64)
+        if err != nil {
+            return nil, fmt.Errorf("invalid %s chat ID for %s: %w", key, lang, err)
+        }
+        chats[strings.TrimSpace(lang)] = id
+    }
+    return chats, nil
+}
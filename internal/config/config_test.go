@@ -0,0 +1,69 @@
+package config
+
+import (
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+)
+
+// setRequiredEnv sets the variables Load can't do without
+func setRequiredEnv(t *testing.T) {
+    t.Setenv("POSTGRES_DSN", "postgres://localhost/ai_newsbot")
+    t.Setenv("TELEGRAM_BOT_TOKEN", "token")
+    t.Setenv("TELEGRAM_CHAT_ID", "-1001111111111")
+    t.Setenv("SOURCES_FILE", "")
+}
+
+func TestLoad_TranslationAPIKey(t *testing.T) {
+    tests := []struct {
+        name     string
+        provider string
+        apiKey   string
+        want     string
+    }{
+        {name: "openai falls back to the OpenRouter key", provider: "openai", want: "sk-or-secret"},
+        {name: "default provider falls back to the OpenRouter key", provider: "", want: "sk-or-secret"},
+        {name: "openai prefers its own key", provider: "openai", apiKey: "sk-own", want: "sk-own"},
+        {name: "libretranslate never gets the OpenRouter key", provider: "libretranslate", want: ""},
+        {name: "libretranslate uses its own key", provider: "libretranslate", apiKey: "libre-key", want: "libre-key"},
+        {name: "deepl never gets the OpenRouter key", provider: "deepl", want: ""},
+        {name: "deepl uses its own key", provider: "deepl", apiKey: "deepl-key:fx", want: "deepl-key:fx"},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            setRequiredEnv(t)
+            t.Setenv("OPENROUTER_API_KEY", "sk-or-secret")
+            t.Setenv("TRANSLATION_PROVIDER", tt.provider)
+            t.Setenv("TRANSLATION_API_KEY", tt.apiKey)
+
+            cfg, err := Load()
+            require.NoError(t, err)
+            assert.Equal(t, tt.want, cfg.TranslationAPIKey)
+            assert.Equal(t, "sk-or-secret", cfg.OpenRouterAPIKey)
+        })
+    }
+}
+
+func TestLoad_OpenRouterKeyRequired(t *testing.T) {
+    setRequiredEnv(t)
+    t.Setenv("OPENROUTER_API_KEY", "")
+    t.Setenv("TRANSLATION_API_KEY", "")
+    t.Setenv("TRANSLATION_PROVIDER", "")
+    t.Setenv("TRANSLATION_BASE_URL", "")
+
+    _, err := Load()
+    assert.EqualError(t, err, "OPENROUTER_API_KEY is required")
+
+    // A local OpenAI-compatible server needs no key
+    t.Setenv("TRANSLATION_BASE_URL", "http://localhost:11434/v1")
+    _, err = Load()
+    assert.NoError(t, err)
+
+    // Neither do the other providers
+    t.Setenv("TRANSLATION_BASE_URL", "")
+    t.Setenv("TRANSLATION_PROVIDER", "libretranslate")
+    _, err = Load()
+    assert.NoError(t, err)
+}
@@ -0,0 +1,215 @@
+package config
+
+import (
+    "encoding/json"
+    "fmt"
+    "net/url"
+    "os"
+    "strings"
+)
+
+// Source types
+const (
+    SourceReddit     = "reddit"
+    SourceFeed       = "feed"
+    SourceHackerNews = "hackernews"
+    SourceArxiv      = "arxiv"
+    SourceGitHub     = "github"
+    SourceHTML       = "html"
+)
+
+// Post flags that include and exclude rules can name. Self marks Reddit
+// text posts and link every other post.
+const (
+    FlagNSFW     = "nsfw"
+    FlagSpoiler  = "spoiler"
+    FlagStickied = "stickied"
+    FlagSelf     = "self"
+    FlagLink     = "link"
+    FlagRemoved  = "removed"
+)
+
+// DefaultExcludeFlags are excluded when a source doesn't list its own
+var DefaultExcludeFlags = []string{FlagNSFW, FlagStickied, FlagRemoved}
+
+// Source describes a single place posts are fetched from. Sources come from
+// the JSON file named by SOURCES_FILE, or from REDDIT_URLS when it is unset.
+type Source struct {
+    // Type selects the scraper for the source; it defaults to reddit
+    Type string `json:"type"`
+    URL  string `json:"url"`
+    // UpvoteThreshold is the minimum score a post needs. Zero means the
+    // global UPVOTE_THRESHOLD applies to Reddit sources and no threshold
+    // to the others.
+    UpvoteThreshold int `json:"upvote_threshold"`
+    // PageDepth is how many listing pages to follow. Zero means one page.
+    PageDepth int `json:"page_depth"`
+    // Keywords keeps only posts mentioning at least one of them, ignoring
+    // case. Empty keeps every post.
+    Keywords []string `json:"keywords"`
+    // Categories keeps only arXiv papers listed in at least one of them,
+    // such as cs.CL. Empty keeps every paper.
+    Categories []string `json:"categories"`
+    // SkipPrereleases ignores GitHub releases marked as pre-releases
+    SkipPrereleases bool `json:"skip_prereleases"`
+    // Selectors locate posts on the page of an html source
+    Selectors HTMLSelectors `json:"selectors"`
+    // Include keeps only posts matching its rules; Exclude drops posts
+    // matching any of its rules
+    Include PostRules `json:"include"`
+    Exclude PostRules `json:"exclude"`
+    // HTTP changes how the source is fetched
+    HTTP HTTPSettings `json:"http"`
+}
+
+// HTTPSettings route a source through proxies and override the User-Agent,
+// headers and TLS settings of its requests. Proxies are http, https or
+// socks5 URLs. With more than one, requests move on to the next proxy
+// whenever the source answers 403 or 429.
+type HTTPSettings struct {
+    Proxies   []string          `json:"proxies"`
+    UserAgent string            `json:"user_agent"`
+    Headers   map[string]string `json:"headers"`
+    TLS       TLSSettings       `json:"tls"`
+}
+
+// TLSSettings adjust certificate checks for a source. CAFile adds PEM
+// certificates to the system roots; CertFile and KeyFile present a client
+// certificate. MinVersion is "1.2" or "1.3".
+type TLSSettings struct {
+    CAFile             string `json:"ca_file"`
+    CertFile           string `json:"cert_file"`
+    KeyFile            string `json:"key_file"`
+    ServerName         string `json:"server_name"`
+    MinVersion         string `json:"min_version"`
+    InsecureSkipVerify bool   `json:"insecure_skip_verify"`
+}
+
+// Custom reports whether the source needs its own transport rather than
+// the shared one
+func (s HTTPSettings) Custom() bool {
+    return len(s.Proxies) > 0 || s.TLS != TLSSettings{}
+}
+
+// PostRules match posts by Reddit flair, ignoring case, or by flag. A post
+// matches when it has any of the listed flairs or flags. Exclude.Flags
+// defaults to DefaultExcludeFlags when unset; an empty list excludes none.
+type PostRules struct {
+    Flairs []string `json:"flairs"`
+    Flags  []string `json:"flags"`
+}
+
+// HTMLSelectors are the CSS selectors of an html source. Each is matched
+// inside Container and may end in @attr to read an attribute instead of
+// the text; a bare @attr reads an attribute of the container itself.
+// Container and Title are required.
+type HTMLSelectors struct {
+    Container string `json:"container"`
+    // ID identifies a post; without it the link, then the title is used
+    ID    string `json:"id"`
+    Title string `json:"title"`
+    Body  string `json:"body"`
+    Link  string `json:"link"`
+    Media string `json:"media"`
+    Score string `json:"score"`
+    Date  string `json:"date"`
+}
+
+// LoadSources reads and validates a sources file on its own, without the
+// UPVOTE_THRESHOLD default
+func LoadSources(path string) ([]Source, error) {
+    return loadSources(path, nil, 0)
+}
+
+// loadSources reads the sources file at path, or builds one source per URL
+// when path is empty
+func loadSources(path string, urls []string, defaultThreshold int) ([]Source, error) {
+    var sources []Source
+
+    if path == "" {
+        for _, u := range urls {
+            sources = append(sources, Source{URL: strings.TrimSpace(u)})
+        }
+    } else {
+        data, err := os.ReadFile(path)
+        if err != nil {
+            return nil, fmt.Errorf("failed to read sources file: %w", err)
+        }
+        if err := json.Unmarshal(data, &sources); err != nil {
+            return nil, fmt.Errorf("failed to parse sources file: %w", err)
+        }
+    }
+
+    for i := range sources {
+        if sources[i].URL == "" {
+            return nil, fmt.Errorf("source %d has no url", i)
+        }
+        switch sources[i].Type {
+        case "":
+            sources[i].Type = SourceReddit
+        case SourceReddit, SourceFeed, SourceHackerNews, SourceArxiv, SourceGitHub:
+        case SourceHTML:
+            if sources[i].Selectors.Container == "" || sources[i].Selectors.Title == "" {
+                return nil, fmt.Errorf("html source %s needs container and title selectors", sources[i].URL)
+            }
+        default:
+            return nil, fmt.Errorf("source %s has unknown type %q", sources[i].URL, sources[i].Type)
+        }
+        if sources[i].UpvoteThreshold == 0 && sources[i].Type == SourceReddit {
+            sources[i].UpvoteThreshold = defaultThreshold
+        }
+        if sources[i].PageDepth < 1 {
+            sources[i].PageDepth = 1
+        }
+        if sources[i].Exclude.Flags == nil {
+            sources[i].Exclude.Flags = DefaultExcludeFlags
+        }
+        for _, flags := range [][]string{sources[i].Include.Flags, sources[i].Exclude.Flags} {
+            for _, flag := range flags {
+                if !validFlag(flag) {
+                    return nil, fmt.Errorf("source %s has unknown flag %q", sources[i].URL, flag)
+                }
+            }
+        }
+        if err := validateHTTP(sources[i].HTTP); err != nil {
+            return nil, fmt.Errorf("source %s: %w", sources[i].URL, err)
+        }
+    }
+
+    return sources, nil
+}
+
+func validFlag(flag string) bool {
+    switch flag {
+    case FlagNSFW, FlagSpoiler, FlagStickied, FlagSelf, FlagLink, FlagRemoved:
+        return true
+    }
+    return false
+}
+
+func validateHTTP(settings HTTPSettings) error {
+    for _, proxy := range settings.Proxies {
+        u, err := url.Parse(proxy)
+        if err != nil {
+            return fmt.Errorf("invalid proxy %q: %w", proxy, err)
+        }
+        switch u.Scheme {
+        case "http", "https", "socks5", "socks5h":
+        default:
+            return fmt.Errorf("proxy %q must be an http, https or socks5 URL", proxy)
+        }
+        if u.Host == "" {
+            return fmt.Errorf("proxy %q has no host", proxy)
+        }
+    }
+
+    switch settings.TLS.MinVersion {
+    case "", "1.2", "1.3":
+    default:
+        return fmt.Errorf("unsupported TLS min_version %q, want 1.2 or 1.3", settings.TLS.MinVersion)
+    }
+    if (settings.TLS.CertFile == "") != (settings.TLS.KeyFile == "") {
+        return fmt.Errorf("TLS cert_file and key_file must be set together")
+    }
+    return nil
+}
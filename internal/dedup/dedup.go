@@ -0,0 +1,134 @@
+package dedup
+
+import (
+    "context"
+    "fmt"
+    "log"
+    "sort"
+
+    "github.com/w1zzzle/ai-newsbot/internal/storage"
+)
+
+// Store finds saved stories and records copies of them
+type Store interface {
+    // FindDuplicate returns the ID of a saved post telling the same story:
+    // one with the same canonical URL, the post's crosspost parent, or a
+    // recent one whose title hash is within maxDistance bits
+    FindDuplicate(ctx context.Context, post storage.Post, maxDistance int) (string, bool, error)
+    // SaveDuplicate records post as a copy of the saved post originalID, so
+    // it counts as seen but is never published
+    SaveDuplicate(ctx context.Context, post storage.Post, originalID string) error
+}
+
+// Detector spots the same story arriving from several sources, such as an
+// announcement posted to three subreddits at once
+type Detector struct {
+    store    Store
+    resolver *Resolver
+    // MaxDistance is the most bits two title hashes may differ in for the
+    // titles to count as the same story
+    MaxDistance int
+}
+
+// NewDetector creates a detector backed by store. A nil resolver leaves
+// shortened links as they are.
+func NewDetector(store Store, resolver *Resolver) *Detector {
+    return &Detector{
+        store:       store,
+        resolver:    resolver,
+        MaxDistance: 3,
+    }
+}
+
+// Fingerprint fills in the canonical URL and title hash of a post. Feed,
+// GitHub and HTML posts only have a permalink, which is the story's own
+// page; every other post's permalink is unique to it, so falling back to
+// it never matches a different story.
+func (d *Detector) Fingerprint(ctx context.Context, post *storage.Post) {
+    link := post.URL
+    if link != "" && d.resolver != nil {
+        link = d.resolver.Resolve(ctx, link)
+    }
+    if link == "" {
+        link = post.Permalink
+    }
+    post.CanonicalURL = CanonicalURL(link)
+    post.TitleHash = int64(TitleHash(post.Title))
+}
+
+// Filter fingerprints posts and drops copies of a story, keeping the
+// earliest post of each. Copies of stories saved in earlier runs are
+// merged into them; copies within posts are simply dropped, so they are
+// considered again if their original isn't saved.
+func (d *Detector) Filter(ctx context.Context, posts []storage.Post) []storage.Post {
+    order := make([]int, len(posts))
+    for i := range posts {
+        d.Fingerprint(ctx, &posts[i])
+        order[i] = i
+    }
+    sort.SliceStable(order, func(i, j int) bool {
+        return posts[order[i]].CreatedAt.Before(posts[order[j]].CreatedAt)
+    })
+
+    // Dropped copies stay in batch, so a third copy matching only the
+    // second is caught too
+    drop := make(map[int]bool)
+    var batch []storage.Post
+    for _, i := range order {
+        post := posts[i]
+        batch = append(batch, post)
+
+        if original, reason, ok := d.matchAny(post, batch[:len(batch)-1]); ok {
+            log.Printf("Skipping post %s: same story as %s (%s)", post.RedditID, original, reason)
+            drop[i] = true
+            continue
+        }
+
+        originalID, found, err := d.store.FindDuplicate(ctx, post, d.MaxDistance)
+        if err != nil {
+            log.Printf("Error looking up duplicates of post %s: %v", post.RedditID, err)
+        } else if found {
+            log.Printf("Merging post %s into saved post %s", post.RedditID, originalID)
+            if err := d.store.SaveDuplicate(ctx, post, originalID); err != nil {
+                log.Printf("Failed to save duplicate post %s: %v", post.RedditID, err)
+            }
+            drop[i] = true
+        }
+    }
+
+    var unique []storage.Post
+    for i, post := range posts {
+        if !drop[i] {
+            unique = append(unique, post)
+        }
+    }
+    return unique
+}
+
+// matchAny returns the first of others telling the same story as post
+func (d *Detector) matchAny(post storage.Post, others []storage.Post) (string, string, bool) {
+    for _, other := range others {
+        if reason, ok := d.Match(post, other); ok {
+            return other.RedditID, reason, true
+        }
+    }
+    return "", "", false
+}
+
+// Match reports whether two fingerprinted posts tell the same story, and
+// why
+func (d *Detector) Match(a, b storage.Post) (string, bool) {
+    switch {
+    case a.CanonicalURL != "" && a.CanonicalURL == b.CanonicalURL:
+        return "same link", true
+    case a.CrosspostParent != "" && (a.CrosspostParent == b.RedditID || a.CrosspostParent == b.CrosspostParent):
+        return "crosspost", true
+    case b.CrosspostParent != "" && b.CrosspostParent == a.RedditID:
+        return "crosspost", true
+    case a.TitleHash != 0 && b.TitleHash != 0:
+        if distance := Distance(uint64(a.TitleHash), uint64(b.TitleHash)); distance <= d.MaxDistance {
+            return fmt.Sprintf("similar title, %d bits apart", distance), true
+        }
+    }
+    return "", false
+}
@@ -0,0 +1,185 @@
+package dedup
+
+import (
+    "context"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "sync/atomic"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "github.com/w1zzzle/ai-newsbot/internal/storage"
+)
+
+func TestCanonicalURL(t *testing.T) {
+    tests := []struct {
+        raw      string
+        expected string
+    }{
+        {"https://openai.com/index/gpt-5/", "https://openai.com/index/gpt-5"},
+        {"http://www.OpenAI.com/index/gpt-5?utm_source=twitter&utm_medium=social", "https://openai.com/index/gpt-5"},
+        {"https://example.com/post?id=7&fbclid=abc&page=2#comments", "https://example.com/post?id=7&page=2"},
+        {"https://example.com/post?page=2&id=7", "https://example.com/post?id=7&page=2"},
+        {"https://example.com:443/", "https://example.com"},
+        {"mailto:someone@example.com", ""},
+        {"", ""},
+    }
+
+    for _, tt := range tests {
+        assert.Equal(t, tt.expected, CanonicalURL(tt.raw), tt.raw)
+    }
+}
+
+func TestTitleHash(t *testing.T) {
+    original := TitleHash("OpenAI announces GPT-5 with improved reasoning")
+
+    assert.Equal(t, original, TitleHash("[N] OpenAI announces GPT-5 with improved reasoning!"))
+    assert.LessOrEqual(t, Distance(original, TitleHash("OpenAI announces GPT-5, with much improved reasoning")), 4)
+    assert.Greater(t, Distance(original, TitleHash("Google releases Gemini 2 with better coding")), 3)
+    assert.Greater(t, Distance(original, TitleHash("OpenAI announces GPT-4o with improved vision")), 3)
+
+    // Too short to compare
+    assert.Zero(t, TitleHash("GPT-5 released"))
+}
+
+// memoryStore is a Store over a slice of saved posts
+type memoryStore struct {
+    saved      []storage.Post
+    duplicates map[string]string
+}
+
+func (s *memoryStore) FindDuplicate(ctx context.Context, post storage.Post, maxDistance int) (string, bool, error) {
+    detector := &Detector{MaxDistance: maxDistance}
+    for _, saved := range s.saved {
+        if _, ok := detector.Match(post, saved); ok {
+            return saved.RedditID, true, nil
+        }
+    }
+    return "", false, nil
+}
+
+func (s *memoryStore) SaveDuplicate(ctx context.Context, post storage.Post, originalID string) error {
+    s.duplicates[post.RedditID] = originalID
+    return nil
+}
+
+func TestDetector_Filter(t *testing.T) {
+    now := time.Now()
+    store := &memoryStore{
+        saved: []storage.Post{
+            {RedditID: "old1", CanonicalURL: "https://anthropic.com/news/claude"},
+        },
+        duplicates: make(map[string]string),
+    }
+    detector := NewDetector(store, nil)
+
+    posts := []storage.Post{
+        {RedditID: "ml2", Title: "OpenAI announces GPT-5 with improved reasoning", URL: "https://openai.com/index/gpt-5/?utm_source=reddit", CreatedAt: now.Add(-time.Hour)},
+        {RedditID: "oa1", Title: "GPT-5 is here", URL: "https://www.openai.com/index/gpt-5", CreatedAt: now.Add(-2 * time.Hour)},
+        {RedditID: "ai3", Title: "[N] OpenAI announces GPT-5 with improved reasoning", CreatedAt: now},
+        {RedditID: "xp4", Title: "Crossposted", CrosspostParent: "oa1", CreatedAt: now},
+        {RedditID: "cl5", Title: "Claude news", URL: "https://anthropic.com/news/claude#top", CreatedAt: now},
+        {RedditID: "un6", Title: "A completely different story about robots", CreatedAt: now},
+    }
+
+    unique := detector.Filter(context.Background(), posts)
+    require.Len(t, unique, 2)
+
+    // The earliest copy of the GPT-5 story is kept; a copy matching only
+    // another copy is dropped as well
+    assert.Equal(t, "oa1", unique[0].RedditID)
+    assert.Equal(t, "https://openai.com/index/gpt-5", unique[0].CanonicalURL)
+    assert.Equal(t, "un6", unique[1].RedditID)
+    assert.NotZero(t, unique[1].TitleHash)
+
+    // Only copies of saved posts are merged
+    assert.Equal(t, map[string]string{"cl5": "old1"}, store.duplicates)
+}
+
+func TestDetector_Filter_FeedAndRedditPost(t *testing.T) {
+    now := time.Now()
+    detector := NewDetector(&memoryStore{duplicates: make(map[string]string)}, nil)
+
+    // Feed items link to the story through their permalink only
+    posts := []storage.Post{
+        {RedditID: "feed:openai-gpt-5", Title: "Introducing GPT-5", Permalink: "https://openai.com/index/gpt-5/", CreatedAt: now.Add(-time.Hour)},
+        {RedditID: "rd1", Title: "OpenAI just shipped their new model", URL: "https://openai.com/index/gpt-5?utm_source=reddit", Permalink: "https://www.reddit.com/r/OpenAI/comments/rd1/", CreatedAt: now},
+    }
+
+    unique := detector.Filter(context.Background(), posts)
+    require.Len(t, unique, 1)
+    assert.Equal(t, "feed:openai-gpt-5", unique[0].RedditID)
+    assert.Equal(t, "https://openai.com/index/gpt-5", unique[0].CanonicalURL)
+}
+
+func TestDetector_Match(t *testing.T) {
+    detector := NewDetector(nil, nil)
+
+    _, ok := detector.Match(storage.Post{RedditID: "a", CrosspostParent: "z"}, storage.Post{RedditID: "b", CrosspostParent: "z"})
+    assert.True(t, ok)
+
+    _, ok = detector.Match(storage.Post{RedditID: "a"}, storage.Post{RedditID: "b", CrosspostParent: "a"})
+    assert.True(t, ok)
+
+    _, ok = detector.Match(storage.Post{RedditID: "a"}, storage.Post{RedditID: "b"})
+    assert.False(t, ok)
+}
+
+func TestResolver_Resolve(t *testing.T) {
+    var target string
+    var requests int32
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        atomic.AddInt32(&requests, 1)
+        switch r.URL.Path {
+        case "/short":
+            http.Redirect(w, r, target, http.StatusMovedPermanently)
+        case "/amp/gpt-5":
+            http.Redirect(w, r, "/articles/gpt-5", http.StatusFound)
+        case "/gone":
+            w.WriteHeader(http.StatusNotFound)
+        default:
+            w.WriteHeader(http.StatusOK)
+        }
+    }))
+    defer server.Close()
+    target = server.URL + "/articles/gpt-5"
+
+    // Route every host to the test server
+    client := server.Client()
+    client.Transport = rewriteHost{server.URL, client.Transport}
+    resolver := NewResolver(client)
+
+    assert.Equal(t, target, resolver.Resolve(context.Background(), "https://bit.ly/short"))
+    assert.Equal(t, "https://news.example.com/articles/gpt-5", resolver.Resolve(context.Background(), "https://news.example.com/amp/gpt-5"))
+    assert.Equal(t, "https://openai.com/index/gpt-5", resolver.Resolve(context.Background(), "https://openai.com/index/gpt-5"))
+    assert.Equal(t, "https://example.com/gone", resolver.Resolve(context.Background(), "https://example.com/gone"))
+    assert.Equal(t, "ftp://example.com/file", resolver.Resolve(context.Background(), "ftp://example.com/file"))
+
+    // Links already followed are answered from the cache
+    made := atomic.LoadInt32(&requests)
+    assert.Equal(t, target, resolver.Resolve(context.Background(), "https://bit.ly/short"))
+    assert.Equal(t, "https://example.com/gone", resolver.Resolve(context.Background(), "https://example.com/gone"))
+    assert.Equal(t, made, atomic.LoadInt32(&requests))
+}
+
+// rewriteHost sends every request to a test server
+type rewriteHost struct {
+    base string
+    next http.RoundTripper
+}
+
+func (r rewriteHost) RoundTrip(req *http.Request) (*http.Response, error) {
+    out := req.Clone(req.Context())
+    out.URL.Scheme = "http"
+    out.URL.Host = strings.TrimPrefix(r.base, "http://")
+
+    // Redirects resolve against the URL the client asked for
+    resp, err := r.next.RoundTrip(out)
+    if resp != nil {
+        resp.Request = req
+    }
+    return resp, err
+}
@@ -0,0 +1,62 @@
+package dedup
+
+import (
+    "hash/fnv"
+    "math/bits"
+    "regexp"
+    "strings"
+)
+
+var (
+    // bracketTags are subreddit conventions such as [R], [N] or [D]
+    bracketTags = regexp.MustCompile(`\[[^\]]*\]|\([^)]*\)`)
+    nonWord     = regexp.MustCompile(`[^\p{L}\p{N}]+`)
+)
+
+// minTitleWords keeps short titles, which collide too easily, out of
+// similarity matching
+const minTitleWords = 4
+
+// NormalizeTitle lowercases a title and strips tags and punctuation
+func NormalizeTitle(title string) string {
+    title = bracketTags.ReplaceAllString(strings.ToLower(title), " ")
+    return strings.Join(strings.Fields(nonWord.ReplaceAllString(title, " ")), " ")
+}
+
+// TitleHash returns the SimHash of a normalized title, built from its
+// character trigrams so a changed word or suffix flips only a few bits. It
+// returns 0 for titles too short to compare.
+func TitleHash(title string) uint64 {
+    normalized := NormalizeTitle(title)
+    if len(strings.Fields(normalized)) < minTitleWords {
+        return 0
+    }
+
+    var weights [64]int
+    runes := []rune(normalized)
+    for i := 0; i+3 <= len(runes); i++ {
+        h := fnv.New64a()
+        h.Write([]byte(string(runes[i : i+3])))
+        sum := h.Sum64()
+        for bit := 0; bit < 64; bit++ {
+            if sum&(1<<bit) != 0 {
+                weights[bit]++
+            } else {
+                weights[bit]--
+            }
+        }
+    }
+
+    var hash uint64
+    for bit, weight := range weights {
+        if weight > 0 {
+            hash |= 1 << bit
+        }
+    }
+    return hash
+}
+
+// Distance is the number of bits in which two hashes differ
+func Distance(a, b uint64) int {
+    return bits.OnesCount64(a ^ b)
+}
@@ -0,0 +1,149 @@
+package dedup
+
+import (
+    "context"
+    "net/http"
+    "net/url"
+    "strings"
+    "sync"
+    "time"
+)
+
+// trackingParams are query parameters that identify a campaign or a
+// referrer, not the page
+var trackingParams = map[string]bool{
+    "fbclid":   true,
+    "gclid":    true,
+    "dclid":    true,
+    "msclkid":  true,
+    "mc_cid":   true,
+    "mc_eid":   true,
+    "ref":      true,
+    "ref_src":  true,
+    "ref_url":  true,
+    "igshid":   true,
+    "si":       true,
+    "_hsenc":   true,
+    "_hsmi":    true,
+    "cmpid":    true,
+    "ncid":     true,
+    "sr_share": true,
+}
+
+// CanonicalURL normalizes a URL so that links to the same page compare
+// equal: the scheme becomes https, the host loses its www. prefix and
+// case, tracking parameters and fragments are dropped, remaining
+// parameters are sorted and a trailing slash is removed. It returns an
+// empty string for anything that isn't an http(s) URL.
+func CanonicalURL(raw string) string {
+    u, err := url.Parse(strings.TrimSpace(raw))
+    if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+        return ""
+    }
+
+    u.Scheme = "https"
+    u.Host = strings.TrimPrefix(strings.ToLower(u.Host), "www.")
+    u.Host = strings.TrimSuffix(u.Host, ":443")
+    u.Host = strings.TrimSuffix(u.Host, ":80")
+    u.User = nil
+    u.Fragment = ""
+    u.RawFragment = ""
+
+    query := u.Query()
+    for key := range query {
+        if strings.HasPrefix(strings.ToLower(key), "utm_") || trackingParams[strings.ToLower(key)] {
+            query.Del(key)
+        }
+    }
+    // Encode sorts by key, keeping the values of each key in order
+    u.RawQuery = query.Encode()
+
+    u.Path = strings.TrimSuffix(u.Path, "/")
+    u.RawPath = ""
+
+    return u.String()
+}
+
+// maxResolved bounds the resolver's cache; it starts over when full
+const maxResolved = 10000
+
+// Resolver follows the redirects of links, from shorteners and otherwise,
+// to the page behind them. Results are cached, as the same links keep
+// coming back in listings.
+type Resolver struct {
+    client *http.Client
+
+    mu       sync.Mutex
+    resolved map[string]string
+}
+
+// NewResolver creates a resolver. A nil client uses one with a 10 second
+// timeout.
+func NewResolver(client *http.Client) *Resolver {
+    if client == nil {
+        client = &http.Client{Timeout: 10 * time.Second}
+    }
+    return &Resolver{
+        client:   client,
+        resolved: make(map[string]string),
+    }
+}
+
+// Resolve returns where a link leads after redirects, or the link itself
+// when it can't be followed
+func (r *Resolver) Resolve(ctx context.Context, link string) string {
+    r.mu.Lock()
+    target, ok := r.resolved[link]
+    r.mu.Unlock()
+    if ok {
+        return target
+    }
+
+    target = r.follow(ctx, link)
+    // A cancelled run says nothing about the link
+    if ctx.Err() != nil {
+        return target
+    }
+
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    if len(r.resolved) >= maxResolved {
+        r.resolved = make(map[string]string)
+    }
+    r.resolved[link] = target
+    return target
+}
+
+// follow requests link and returns the URL its redirects end at. The body
+// is never read.
+func (r *Resolver) follow(ctx context.Context, link string) string {
+    u, err := url.Parse(link)
+    if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+        return link
+    }
+
+    // Some servers refuse HEAD, so GET is tried as well
+    for _, method := range []string{http.MethodHead, http.MethodGet} {
+        req, err := http.NewRequestWithContext(ctx, method, link, nil)
+        if err != nil {
+            return link
+        }
+        resp, err := r.client.Do(req)
+        if err != nil {
+            if ctx.Err() != nil {
+                return link
+            }
+            continue
+        }
+        resp.Body.Close()
+        if resp.StatusCode >= http.StatusBadRequest {
+            continue
+        }
+        // resp.Request is the last request made, after any redirects
+        if resp.Request.Response == nil {
+            return link
+        }
+        return resp.Request.URL.String()
+    }
+    return link
+}
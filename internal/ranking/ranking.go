@@ -0,0 +1,80 @@
+package ranking
+
+import (
+    "math"
+    "sort"
+    "time"
+
+    "github.com/w1zzzle/ai-newsbot/internal/storage"
+)
+
+// minAge keeps brand-new posts from getting huge velocities off a handful
+// of early votes
+const minAge = 30 * time.Minute
+
+// Weights controls how much each velocity contributes to a post's rank
+type Weights struct {
+    Score    float64
+    Comments float64
+}
+
+// Ranker orders posts by how fast they are gaining upvotes and comments
+type Ranker struct {
+    weights Weights
+    topN    int
+    now     func() time.Time
+}
+
+// New creates a ranker. topN limits how many posts Select returns; zero
+// means no limit.
+func New(weights Weights, topN int) *Ranker {
+    return &Ranker{
+        weights: weights,
+        topN:    topN,
+        now:     time.Now,
+    }
+}
+
+// Velocity returns upvotes per hour and comments per hour since the post
+// was created
+func Velocity(post storage.Post, now time.Time) (scorePerHour, commentsPerHour float64) {
+    age := now.Sub(post.CreatedAt)
+    if age < minAge {
+        age = minAge
+    }
+    hours := age.Hours()
+
+    return float64(post.Score) / hours, float64(post.NumComments) / hours
+}
+
+// Rank computes the weighted velocity of a post
+func (r *Ranker) Rank(post storage.Post) float64 {
+    scorePerHour, commentsPerHour := Velocity(post, r.now())
+    rank := r.weights.Score*scorePerHour + r.weights.Comments*commentsPerHour
+    if math.IsNaN(rank) {
+        return 0
+    }
+    return rank
+}
+
+// Select returns posts ordered from highest to lowest rank, truncated to
+// the configured top N
+func (r *Ranker) Select(posts []storage.Post) []storage.Post {
+    ranked := make([]storage.Post, len(posts))
+    copy(ranked, posts)
+
+    ranks := make(map[string]float64, len(ranked))
+    for _, post := range ranked {
+        ranks[post.RedditID] = r.Rank(post)
+    }
+
+    sort.SliceStable(ranked, func(i, j int) bool {
+        return ranks[ranked[i].RedditID] > ranks[ranked[j].RedditID]
+    })
+
+    if r.topN > 0 && len(ranked) > r.topN {
+        ranked = ranked[:r.topN]
+    }
+
+    return ranked
+}
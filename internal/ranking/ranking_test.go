@@ -0,0 +1,82 @@
+package ranking
+
+import (
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "github.com/w1zzzle/ai-newsbot/internal/storage"
+)
+
+func newTestRanker(weights Weights, topN int, now time.Time) *Ranker {
+    r := New(weights, topN)
+    r.now = func() time.Time { return now }
+    return r
+}
+
+func TestVelocity(t *testing.T) {
+    now := time.Date(2024, 3, 11, 12, 0, 0, 0, time.UTC)
+
+    post := storage.Post{Score: 600, NumComments: 90, CreatedAt: now.Add(-3 * time.Hour)}
+    scorePerHour, commentsPerHour := Velocity(post, now)
+    assert.InDelta(t, 200, scorePerHour, 0.001)
+    assert.InDelta(t, 30, commentsPerHour, 0.001)
+
+    // Posts younger than minAge are treated as minAge old
+    fresh := storage.Post{Score: 50, NumComments: 5, CreatedAt: now.Add(-time.Minute)}
+    scorePerHour, commentsPerHour = Velocity(fresh, now)
+    assert.InDelta(t, 100, scorePerHour, 0.001)
+    assert.InDelta(t, 10, commentsPerHour, 0.001)
+}
+
+func TestRanker_Select_PrefersRisingPosts(t *testing.T) {
+    now := time.Date(2024, 3, 11, 12, 0, 0, 0, time.UTC)
+    ranker := newTestRanker(Weights{Score: 1, Comments: 0}, 0, now)
+
+    posts := []storage.Post{
+        {RedditID: "old", Score: 5000, CreatedAt: now.Add(-48 * time.Hour)},  // ~104/h
+        {RedditID: "rising", Score: 900, CreatedAt: now.Add(-2 * time.Hour)}, // 450/h
+        {RedditID: "slow", Score: 150, CreatedAt: now.Add(-10 * time.Hour)},  // 15/h
+    }
+
+    ranked := ranker.Select(posts)
+    require.Len(t, ranked, 3)
+    assert.Equal(t, "rising", ranked[0].RedditID)
+    assert.Equal(t, "old", ranked[1].RedditID)
+    assert.Equal(t, "slow", ranked[2].RedditID)
+}
+
+func TestRanker_Select_CommentWeight(t *testing.T) {
+    now := time.Date(2024, 3, 11, 12, 0, 0, 0, time.UTC)
+
+    posts := []storage.Post{
+        {RedditID: "upvoted", Score: 400, NumComments: 10, CreatedAt: now.Add(-time.Hour)},
+        {RedditID: "discussed", Score: 250, NumComments: 120, CreatedAt: now.Add(-time.Hour)},
+    }
+
+    byScore := newTestRanker(Weights{Score: 1, Comments: 0}, 0, now).Select(posts)
+    assert.Equal(t, "upvoted", byScore[0].RedditID)
+
+    byComments := newTestRanker(Weights{Score: 1, Comments: 3}, 0, now).Select(posts)
+    assert.Equal(t, "discussed", byComments[0].RedditID)
+}
+
+func TestRanker_Select_TopN(t *testing.T) {
+    now := time.Date(2024, 3, 11, 12, 0, 0, 0, time.UTC)
+    ranker := newTestRanker(Weights{Score: 1, Comments: 1}, 2, now)
+
+    posts := []storage.Post{
+        {RedditID: "a", Score: 100, CreatedAt: now.Add(-time.Hour)},
+        {RedditID: "b", Score: 300, CreatedAt: now.Add(-time.Hour)},
+        {RedditID: "c", Score: 200, CreatedAt: now.Add(-time.Hour)},
+    }
+
+    ranked := ranker.Select(posts)
+    require.Len(t, ranked, 2)
+    assert.Equal(t, "b", ranked[0].RedditID)
+    assert.Equal(t, "c", ranked[1].RedditID)
+
+    // The input slice is left untouched
+    assert.Equal(t, "a", posts[0].RedditID)
+}
@@ -0,0 +1,102 @@
+package rules
+
+import (
+    "fmt"
+    "strings"
+)
+
+// Reason describes why a rule rejected the post, following the first
+// failure down the nested rules. It is empty when the rule matched.
+func (ev Evaluation) Reason() string {
+    if ev.Matched {
+        return ""
+    }
+
+    for _, check := range ev.Checks {
+        if !check.Passed {
+            return fmt.Sprintf("rule %s: %s %s", ev.Rule, check.Condition, check.Detail)
+        }
+    }
+    for _, child := range ev.All {
+        if !child.Matched {
+            return child.Reason()
+        }
+    }
+    for _, child := range ev.None {
+        if child.Matched {
+            return fmt.Sprintf("rule %s: excluded by rule %s", ev.Rule, child.Rule)
+        }
+    }
+    if len(ev.Any) > 0 {
+        names := make([]string, len(ev.Any))
+        for i, child := range ev.Any {
+            names[i] = child.Rule
+        }
+        return fmt.Sprintf("rule %s: none of %s matched", ev.Rule, strings.Join(names, ", "))
+    }
+
+    return fmt.Sprintf("rule %s did not match", ev.Rule)
+}
+
+// MatchedRules returns the names of the named rules that matched, in the
+// order they were evaluated
+func (ev Evaluation) MatchedRules() []string {
+    var names []string
+    ev.walk(func(e Evaluation) {
+        if e.Matched && e.Rule != unnamedRule {
+            names = append(names, e.Rule)
+        }
+    })
+    return names
+}
+
+func (ev Evaluation) walk(visit func(Evaluation)) {
+    visit(ev)
+    for _, group := range [][]Evaluation{ev.All, ev.Any, ev.None} {
+        for _, child := range group {
+            child.walk(visit)
+        }
+    }
+}
+
+// String renders the evaluation as an indented tree, marking each rule and
+// condition that held with ✓ and each that failed with ✗
+func (ev Evaluation) String() string {
+    var b strings.Builder
+    ev.write(&b, 0)
+    return strings.TrimSuffix(b.String(), "\n")
+}
+
+func (ev Evaluation) write(b *strings.Builder, depth int) {
+    indent := strings.Repeat("  ", depth)
+    fmt.Fprintf(b, "%s%s rule %s\n", indent, mark(ev.Matched), ev.Rule)
+
+    for _, check := range ev.Checks {
+        fmt.Fprintf(b, "%s  %s %s: %s\n", indent, mark(check.Passed), check.Condition, check.Detail)
+    }
+
+    groups := []struct {
+        title string
+        rules []Evaluation
+    }{
+        {"all of", ev.All},
+        {"any of", ev.Any},
+        {"none of", ev.None},
+    }
+    for _, group := range groups {
+        if len(group.rules) == 0 {
+            continue
+        }
+        fmt.Fprintf(b, "%s  %s:\n", indent, group.title)
+        for _, child := range group.rules {
+            child.write(b, depth+2)
+        }
+    }
+}
+
+func mark(passed bool) string {
+    if passed {
+        return "✓"
+    }
+    return "✗"
+}
@@ -0,0 +1,302 @@
+package rules
+
+import (
+    "encoding/json"
+    "fmt"
+    "net/url"
+    "os"
+    "regexp"
+    "strings"
+    "unicode/utf8"
+
+    "github.com/w1zzzle/ai-newsbot/internal/storage"
+)
+
+// Rule is a set of conditions on a post. A rule matches when every
+// condition it sets holds, every rule in All matches, at least one rule in
+// Any matches and no rule in None matches. A rule without conditions or
+// groups matches every post.
+type Rule struct {
+    // Name identifies the rule in logs and explanations
+    Name string `json:"name"`
+
+    // Keywords requires the title or body to mention one of them, and
+    // ExcludeKeywords that it mentions none. Both ignore case.
+    Keywords        []string `json:"keywords"`
+    ExcludeKeywords []string `json:"exclude_keywords"`
+    // TitleRegex and BodyRegex must match the title and body
+    TitleRegex string `json:"title_regex"`
+    BodyRegex  string `json:"body_regex"`
+    // Domains requires the linked page to be on one of them, and
+    // ExcludeDomains on none. Subdomains count as their parent domain;
+    // posts without a link are judged by their permalink.
+    Domains        []string `json:"domains"`
+    ExcludeDomains []string `json:"exclude_domains"`
+    // MinBodyLength is the shortest body, in characters, that matches
+    MinBodyLength int `json:"min_body_length"`
+
+    All  []Rule `json:"all"`
+    Any  []Rule `json:"any"`
+    None []Rule `json:"none"`
+}
+
+// unnamedRule stands in for the name of rules that have none
+const unnamedRule = "(unnamed)"
+
+// Engine decides which posts the pipeline keeps
+type Engine struct {
+    root *compiledRule
+}
+
+type compiledRule struct {
+    Rule
+    title, body    *regexp.Regexp
+    all, any, none []*compiledRule
+}
+
+// Load reads the rules file at path. The file holds a single root rule.
+func Load(path string) (*Engine, error) {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return nil, fmt.Errorf("failed to read rules file: %w", err)
+    }
+
+    var root Rule
+    if err := json.Unmarshal(data, &root); err != nil {
+        return nil, fmt.Errorf("failed to parse rules file: %w", err)
+    }
+
+    return New(root)
+}
+
+// New creates an engine keeping the posts root matches
+func New(root Rule) (*Engine, error) {
+    compiled, err := compile(root, "root")
+    if err != nil {
+        return nil, err
+    }
+    return &Engine{root: compiled}, nil
+}
+
+func compile(rule Rule, path string) (*compiledRule, error) {
+    if rule.Name != "" {
+        path = rule.Name
+    }
+
+    c := &compiledRule{Rule: rule}
+    var err error
+    if rule.TitleRegex != "" {
+        if c.title, err = regexp.Compile(rule.TitleRegex); err != nil {
+            return nil, fmt.Errorf("rule %s has invalid title_regex: %w", path, err)
+        }
+    }
+    if rule.BodyRegex != "" {
+        if c.body, err = regexp.Compile(rule.BodyRegex); err != nil {
+            return nil, fmt.Errorf("rule %s has invalid body_regex: %w", path, err)
+        }
+    }
+
+    groups := []struct {
+        name  string
+        rules []Rule
+        into  *[]*compiledRule
+    }{
+        {"all", rule.All, &c.all},
+        {"any", rule.Any, &c.any},
+        {"none", rule.None, &c.none},
+    }
+    for _, group := range groups {
+        for i, child := range group.rules {
+            compiled, err := compile(child, fmt.Sprintf("%s.%s[%d]", path, group.name, i))
+            if err != nil {
+                return nil, err
+            }
+            *group.into = append(*group.into, compiled)
+        }
+    }
+
+    return c, nil
+}
+
+// Allows reports whether the pipeline should keep the post
+func (e *Engine) Allows(post storage.Post) bool {
+    return e.Evaluate(post).Matched
+}
+
+// Evaluate judges a post against every rule, recording why each matched
+// or not
+func (e *Engine) Evaluate(post storage.Post) Evaluation {
+    return e.root.evaluate(post)
+}
+
+// Check is the outcome of one condition of a rule
+type Check struct {
+    Condition string
+    Passed    bool
+    Detail    string
+}
+
+// Evaluation explains how a rule and its nested rules judged a post
+type Evaluation struct {
+    Rule    string
+    Matched bool
+    Checks  []Check
+    All     []Evaluation
+    Any     []Evaluation
+    None    []Evaluation
+}
+
+func (c *compiledRule) evaluate(post storage.Post) Evaluation {
+    ev := Evaluation{Rule: c.Name, Checks: c.checks(post)}
+    if ev.Rule == "" {
+        ev.Rule = unnamedRule
+    }
+
+    ev.Matched = true
+    for _, check := range ev.Checks {
+        ev.Matched = ev.Matched && check.Passed
+    }
+
+    // Nested rules are all evaluated, even once the outcome is known, so
+    // an explanation shows every one of them
+    for _, child := range c.all {
+        result := child.evaluate(post)
+        ev.Matched = ev.Matched && result.Matched
+        ev.All = append(ev.All, result)
+    }
+
+    anyMatched := len(c.any) == 0
+    for _, child := range c.any {
+        result := child.evaluate(post)
+        anyMatched = anyMatched || result.Matched
+        ev.Any = append(ev.Any, result)
+    }
+    ev.Matched = ev.Matched && anyMatched
+
+    for _, child := range c.none {
+        result := child.evaluate(post)
+        ev.Matched = ev.Matched && !result.Matched
+        ev.None = append(ev.None, result)
+    }
+
+    return ev
+}
+
+func (c *compiledRule) checks(post storage.Post) []Check {
+    var checks []Check
+    text := strings.ToLower(post.Title + "\n" + post.Body)
+
+    if len(c.Keywords) > 0 {
+        keyword, found := firstMentioned(text, c.Keywords)
+        detail := fmt.Sprintf("mentions %q", keyword)
+        if !found {
+            detail = fmt.Sprintf("mentions none of %s", quoteList(c.Keywords))
+        }
+        checks = append(checks, Check{Condition: "keywords", Passed: found, Detail: detail})
+    }
+    if len(c.ExcludeKeywords) > 0 {
+        keyword, found := firstMentioned(text, c.ExcludeKeywords)
+        detail := fmt.Sprintf("mentions excluded %q", keyword)
+        if !found {
+            detail = fmt.Sprintf("mentions none of %s", quoteList(c.ExcludeKeywords))
+        }
+        checks = append(checks, Check{Condition: "exclude_keywords", Passed: !found, Detail: detail})
+    }
+
+    if c.title != nil {
+        checks = append(checks, regexCheck("title_regex", c.title, post.Title))
+    }
+    if c.body != nil {
+        checks = append(checks, regexCheck("body_regex", c.body, post.Body))
+    }
+
+    domain := postDomain(post)
+    if len(c.Domains) > 0 {
+        passed := inDomains(domain, c.Domains)
+        detail := fmt.Sprintf("%s is listed", domain)
+        if !passed {
+            detail = fmt.Sprintf("%s is not in %s", orNone(domain), quoteList(c.Domains))
+        }
+        checks = append(checks, Check{Condition: "domains", Passed: passed, Detail: detail})
+    }
+    if len(c.ExcludeDomains) > 0 {
+        denied := inDomains(domain, c.ExcludeDomains)
+        detail := fmt.Sprintf("%s is not denied", orNone(domain))
+        if denied {
+            detail = fmt.Sprintf("%s is denied", domain)
+        }
+        checks = append(checks, Check{Condition: "exclude_domains", Passed: !denied, Detail: detail})
+    }
+
+    if c.MinBodyLength > 0 {
+        length := utf8.RuneCountInString(strings.TrimSpace(post.Body))
+        checks = append(checks, Check{
+            Condition: "min_body_length",
+            Passed:    length >= c.MinBodyLength,
+            Detail:    fmt.Sprintf("body has %d of %d characters", length, c.MinBodyLength),
+        })
+    }
+
+    return checks
+}
+
+func regexCheck(condition string, re *regexp.Regexp, value string) Check {
+    if loc := re.FindStringIndex(value); loc != nil {
+        return Check{Condition: condition, Passed: true, Detail: fmt.Sprintf("matches %q", value[loc[0]:loc[1]])}
+    }
+    return Check{Condition: condition, Passed: false, Detail: fmt.Sprintf("does not match /%s/", re)}
+}
+
+// firstMentioned returns the first keyword contained in the lowercased text
+func firstMentioned(text string, keywords []string) (string, bool) {
+    for _, keyword := range keywords {
+        if k := strings.ToLower(strings.TrimSpace(keyword)); k != "" && strings.Contains(text, k) {
+            return keyword, true
+        }
+    }
+    return "", false
+}
+
+// postDomain returns the host of the page a post links to, or of its
+// permalink when it links nowhere, without a www. prefix
+func postDomain(post storage.Post) string {
+    link := post.URL
+    if link == "" {
+        link = post.Permalink
+    }
+
+    u, err := url.Parse(link)
+    if err != nil {
+        return ""
+    }
+    return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
+
+// inDomains reports whether domain is one of domains or a subdomain of one
+func inDomains(domain string, domains []string) bool {
+    if domain == "" {
+        return false
+    }
+    for _, d := range domains {
+        d = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(d)), "www.")
+        if domain == d || strings.HasSuffix(domain, "."+d) {
+            return true
+        }
+    }
+    return false
+}
+
+func quoteList(values []string) string {
+    quoted := make([]string, len(values))
+    for i, v := range values {
+        quoted[i] = fmt.Sprintf("%q", v)
+    }
+    return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+func orNone(domain string) string {
+    if domain == "" {
+        return "(no domain)"
+    }
+    return domain
+}
@@ -0,0 +1,130 @@
+package rules
+
+import (
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "github.com/w1zzzle/ai-newsbot/internal/storage"
+)
+
+var (
+    paperPost = storage.Post{
+        RedditID: "p1",
+        Title:    "[R] Sparse attention for long-context LLMs",
+        Body:     "We propose a sparse attention scheme that scales to a million tokens.",
+        URL:      "https://www.arxiv.org/abs/2403.01234",
+    }
+    hiringPost = storage.Post{
+        RedditID:  "h2",
+        Title:     "[D] Hiring ML engineers for our LLM startup",
+        Body:      "Remote OK.",
+        Permalink: "https://www.reddit.com/r/MachineLearning/comments/h2/",
+    }
+    blogPost = storage.Post{
+        RedditID: "b3",
+        Title:    "How we trained a GPT-style model on a budget",
+        URL:      "https://medium.com/@someone/budget-gpt",
+    }
+)
+
+func mustNew(t *testing.T, root Rule) *Engine {
+    t.Helper()
+    engine, err := New(root)
+    require.NoError(t, err)
+    return engine
+}
+
+func TestEngine_Conditions(t *testing.T) {
+    tests := []struct {
+        name     string
+        rule     Rule
+        post     storage.Post
+        expected bool
+    }{
+        {"empty rule keeps everything", Rule{}, hiringPost, true},
+        {"keyword in title", Rule{Keywords: []string{"llm"}}, paperPost, true},
+        {"keyword in body", Rule{Keywords: []string{"Million Tokens"}}, paperPost, true},
+        {"no keyword", Rule{Keywords: []string{"diffusion"}}, paperPost, false},
+        {"excluded keyword", Rule{ExcludeKeywords: []string{"hiring"}}, hiringPost, false},
+        {"title regex", Rule{TitleRegex: `^\[R\]`}, paperPost, true},
+        {"title regex fails", Rule{TitleRegex: `^\[R\]`}, hiringPost, false},
+        {"body regex", Rule{BodyRegex: `(?i)remote`}, hiringPost, true},
+        {"allowed domain ignores www", Rule{Domains: []string{"arxiv.org"}}, paperPost, true},
+        {"permalink domain", Rule{Domains: []string{"arxiv.org"}}, hiringPost, false},
+        {"denied domain", Rule{ExcludeDomains: []string{"medium.com"}}, blogPost, false},
+        {"min body length", Rule{MinBodyLength: 50}, paperPost, true},
+        {"short body", Rule{MinBodyLength: 50}, hiringPost, false},
+        {"conditions combine with AND", Rule{Keywords: []string{"LLM"}, ExcludeKeywords: []string{"hiring"}}, hiringPost, false},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            assert.Equal(t, tt.expected, mustNew(t, tt.rule).Allows(tt.post))
+        })
+    }
+}
+
+func TestEngine_Groups(t *testing.T) {
+    engine := mustNew(t, Rule{
+        Name: "root",
+        Any: []Rule{
+            {Name: "papers", Domains: []string{"arxiv.org"}},
+            {Name: "llm-news", Keywords: []string{"LLM", "GPT"}, MinBodyLength: 20},
+        },
+        None: []Rule{
+            {Name: "hiring", TitleRegex: `(?i)\bhiring\b`},
+        },
+    })
+
+    assert.True(t, engine.Allows(paperPost))
+    assert.False(t, engine.Allows(hiringPost))
+    assert.False(t, engine.Allows(blogPost))
+
+    assert.Equal(t, []string{"root", "papers", "llm-news"}, engine.Evaluate(paperPost).MatchedRules())
+    assert.Equal(t, "rule root: excluded by rule hiring", engine.Evaluate(hiringPost).Reason())
+    assert.Equal(t, "rule root: none of papers, llm-news matched", engine.Evaluate(blogPost).Reason())
+}
+
+func TestEvaluation_Reason_FollowsAll(t *testing.T) {
+    engine := mustNew(t, Rule{All: []Rule{
+        {Name: "long", MinBodyLength: 5},
+        {Name: "trusted", ExcludeDomains: []string{"medium.com"}},
+    }})
+
+    assert.Equal(t, "rule long: min_body_length body has 0 of 5 characters", engine.Evaluate(blogPost).Reason())
+    assert.Empty(t, engine.Evaluate(paperPost).Reason())
+}
+
+func TestEvaluation_String(t *testing.T) {
+    engine := mustNew(t, Rule{
+        Name:     "root",
+        Keywords: []string{"GPT"},
+        None:     []Rule{{Name: "blogs", Domains: []string{"medium.com"}}},
+    })
+
+    expected := strings.Join([]string{
+        "✗ rule root",
+        `  ✓ keywords: mentions "GPT"`,
+        "  none of:",
+        "    ✓ rule blogs",
+        "      ✓ domains: medium.com is listed",
+    }, "\n")
+    assert.Equal(t, expected, engine.Evaluate(blogPost).String())
+}
+
+func TestLoad(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "rules.json")
+    require.NoError(t, os.WriteFile(path, []byte(`{"any": [{"name": "papers", "domains": ["arxiv.org"]}]}`), 0o644))
+
+    engine, err := Load(path)
+    require.NoError(t, err)
+    assert.True(t, engine.Allows(paperPost))
+
+    require.NoError(t, os.WriteFile(path, []byte(`{"all": [{"name": "bad", "title_regex": "("}]}`), 0o644))
+    _, err = Load(path)
+    assert.ErrorContains(t, err, "rule bad has invalid title_regex")
+}
@@ -0,0 +1,237 @@
+package scraper
+
+import (
+    "context"
+    "fmt"
+    "math"
+    "mime"
+    "net/http"
+    "net/url"
+    "regexp"
+    "strings"
+
+    "github.com/PuerkitoBio/goquery"
+    "github.com/w1zzzle/ai-newsbot/internal/storage"
+    "golang.org/x/net/html"
+)
+
+// maxArticleLength caps the extracted text, in runes, to keep translations
+// short and cheap
+const maxArticleLength = 6000
+
+// Article is what was extracted from a linked page
+type Article struct {
+    Title       string
+    Description string
+    Image       string
+    Text        string
+}
+
+// ArticleExtractor follows the outbound links of link posts and extracts
+// the main text of the page, readability-style
+type ArticleExtractor struct {
+    http *fetcher
+}
+
+var (
+    // Class and id hints of content and of boilerplate around it
+    positiveHints = regexp.MustCompile(`(?i)article|body|content|entry|main|page|post|text|blog|story`)
+    negativeHints = regexp.MustCompile(`(?i)comment|meta|footer|footnote|sidebar|sponsor|\bads?\b|advert|nav|share|social|related|promo|popup|banner|cookie|subscribe|newsletter|header|menu|widget`)
+)
+
+// NewArticleExtractor creates an extractor with its own HTTP layer
+func NewArticleExtractor(opts ...Option) *ArticleExtractor {
+    return &ArticleExtractor{http: newFetcher(opts...)}
+}
+
+// Expand fills in a link post from the page it links to: the article text
+// becomes the body and the OpenGraph image the media, unless the post
+// already has them. Posts without a link are left alone.
+func (e *ArticleExtractor) Expand(ctx context.Context, post *storage.Post) error {
+    if post.URL == "" || strings.TrimSpace(post.Body) != "" {
+        return nil
+    }
+
+    article, err := e.Extract(ctx, post.URL)
+    if err != nil {
+        return err
+    }
+
+    post.Body = article.Text
+    if post.Body == "" {
+        post.Body = article.Description
+    }
+    if len(post.MediaURLs) == 0 && article.Image != "" {
+        post.MediaURLs = []string{article.Image}
+        post.Media = []storage.Media{{URL: article.Image, Type: storage.MediaImage}}
+    }
+    return nil
+}
+
+// Extract fetches a page and returns its OpenGraph metadata and main text
+func (e *ArticleExtractor) Extract(ctx context.Context, pageURL string) (Article, error) {
+    resp, err := e.http.get(ctx, pageURL, http.Header{"Accept": {"text/html,application/xhtml+xml"}})
+    if err != nil {
+        return Article{}, err
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode != http.StatusOK {
+        return Article{}, fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
+    }
+    if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType != "" && mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+        return Article{}, fmt.Errorf("%s is not an HTML page (%s)", pageURL, mediaType)
+    }
+
+    doc, err := goquery.NewDocumentFromReader(resp.Body)
+    if err != nil {
+        return Article{}, err
+    }
+
+    return extractArticle(doc, resp.Request.URL), nil
+}
+
+// extractArticle reads the OpenGraph tags of a page and its main text
+func extractArticle(doc *goquery.Document, base *url.URL) Article {
+    article := Article{
+        Title:       metaContent(doc, "og:title"),
+        Description: metaContent(doc, "og:description", "description"),
+        Image:       resolveURL(base, metaContent(doc, "og:image", "og:image:url", "twitter:image")),
+    }
+    if article.Title == "" {
+        article.Title = collapseSpace(doc.Find("title").First().Text())
+    }
+
+    doc.Find("script, style, noscript, iframe, form, nav, header, footer, aside, svg, button").Remove()
+    if content := topCandidate(doc); content != nil {
+        article.Text = truncateText(articleText(content), maxArticleLength)
+    }
+
+    return article
+}
+
+// metaContent returns the first non-empty <meta> content among names,
+// matching both property= (OpenGraph) and name= attributes
+func metaContent(doc *goquery.Document, names ...string) string {
+    for _, name := range names {
+        selector := fmt.Sprintf(`meta[property=%q], meta[name=%q]`, name, name)
+        if content := strings.TrimSpace(doc.Find(selector).First().AttrOr("content", "")); content != "" {
+            return content
+        }
+    }
+    return ""
+}
+
+// topCandidate scores the parents of every paragraph by the amount of text
+// they hold, like Readability, and returns the best one
+func topCandidate(doc *goquery.Document) *goquery.Selection {
+    scores := make(map[*html.Node]float64)
+    var order []*html.Node
+
+    addScore := func(node *html.Node, score float64) {
+        if node == nil || node.Type != html.ElementNode {
+            return
+        }
+        if _, ok := scores[node]; !ok {
+            scores[node] = classWeight(goquery.NewDocumentFromNode(node).Selection)
+            order = append(order, node)
+        }
+        scores[node] += score
+    }
+
+    doc.Find("p, pre, td, blockquote").Each(func(i int, p *goquery.Selection) {
+        text := collapseSpace(p.Text())
+        if len(text) < 25 {
+            return
+        }
+
+        score := 1 + float64(strings.Count(text, ",")) + math.Min(float64(len(text))/100, 3)
+        parent := p.Get(0).Parent
+        addScore(parent, score)
+        if parent != nil {
+            addScore(parent.Parent, score/2)
+        }
+    })
+
+    var best *html.Node
+    bestScore := 0.0
+    for _, node := range order {
+        sel := goquery.NewDocumentFromNode(node).Selection
+        score := scores[node] * (1 - linkDensity(sel))
+        if score > bestScore {
+            best, bestScore = node, score
+        }
+    }
+
+    if best == nil {
+        return nil
+    }
+    return doc.FindNodes(best)
+}
+
+// classWeight rewards elements whose class or id suggests content and
+// penalises boilerplate
+func classWeight(sel *goquery.Selection) float64 {
+    weight := 0.0
+    for _, attr := range []string{"class", "id"} {
+        value := sel.AttrOr(attr, "")
+        if value == "" {
+            continue
+        }
+        if negativeHints.MatchString(value) {
+            weight -= 25
+        }
+        if positiveHints.MatchString(value) {
+            weight += 25
+        }
+    }
+    return weight
+}
+
+// linkDensity is the share of an element's text that sits inside links
+func linkDensity(sel *goquery.Selection) float64 {
+    textLength := len(collapseSpace(sel.Text()))
+    if textLength == 0 {
+        return 0
+    }
+    linkLength := 0
+    sel.Find("a").Each(func(i int, a *goquery.Selection) {
+        linkLength += len(collapseSpace(a.Text()))
+    })
+    return float64(linkLength) / float64(textLength)
+}
+
+// articleText joins the paragraphs of the content element, skipping
+// link-heavy blocks such as "read more" lists
+func articleText(content *goquery.Selection) string {
+    var paragraphs []string
+    content.Find("p, h2, h3, h4, li, blockquote, pre").Each(func(i int, block *goquery.Selection) {
+        // Nested blocks are read through their parent
+        if block.ParentsFiltered("p, li, blockquote, pre").Length() > 0 {
+            return
+        }
+        text := collapseSpace(block.Text())
+        if text == "" || linkDensity(block) > 0.5 {
+            return
+        }
+        paragraphs = append(paragraphs, text)
+    })
+
+    if len(paragraphs) == 0 {
+        return collapseSpace(content.Text())
+    }
+    return strings.Join(paragraphs, "\n\n")
+}
+
+// truncateText cuts text to at most n runes, preferring a paragraph break
+func truncateText(text string, n int) string {
+    runes := []rune(text)
+    if len(runes) <= n {
+        return text
+    }
+    cut := string(runes[:n])
+    if i := strings.LastIndex(cut, "\n\n"); i > len(cut)/2 {
+        return cut[:i]
+    }
+    return strings.TrimSpace(cut) + "…"
+}
@@ -0,0 +1,114 @@
+package scraper
+
+import (
+    "context"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "github.com/w1zzzle/ai-newsbot/internal/storage"
+)
+
+func articleServer(t *testing.T) *httptest.Server {
+    t.Helper()
+    page := readFixture(t, "article.html")
+    return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        switch r.URL.Path {
+        case "/news/chips":
+            w.Header().Set("Content-Type", "text/html; charset=utf-8")
+            w.Write(page)
+        case "/paper.pdf":
+            w.Header().Set("Content-Type", "application/pdf")
+            w.Write([]byte("%PDF-1.7"))
+        default:
+            w.WriteHeader(http.StatusNotFound)
+        }
+    }))
+}
+
+func TestArticleExtractor_Extract(t *testing.T) {
+    server := articleServer(t)
+    defer server.Close()
+
+    article, err := NewArticleExtractor().Extract(context.Background(), server.URL+"/news/chips")
+    require.NoError(t, err)
+
+    assert.Equal(t, "Chip startup raises $200M for inference silicon", article.Title)
+    assert.Equal(t, "The round values the company at $1.5B.", article.Description)
+    assert.Equal(t, server.URL+"/images/chip-hero.jpg", article.Image)
+
+    paragraphs := strings.Split(article.Text, "\n\n")
+    require.Len(t, paragraphs, 4)
+    assert.Equal(t, "A bet on inference", paragraphs[0])
+    assert.True(t, strings.HasPrefix(paragraphs[1], "The startup, founded by former GPU architects"))
+    assert.True(t, strings.HasPrefix(paragraphs[3], "Samples are expected"))
+
+    // Boilerplate around the article is left out
+    assert.NotContains(t, article.Text, "newsletter")
+    assert.NotContains(t, article.Text, "First!")
+    assert.NotContains(t, article.Text, "Share on")
+    assert.NotContains(t, article.Text, "rights reserved")
+}
+
+func TestArticleExtractor_RejectsNonHTML(t *testing.T) {
+    server := articleServer(t)
+    defer server.Close()
+
+    _, err := NewArticleExtractor().Extract(context.Background(), server.URL+"/paper.pdf")
+    assert.ErrorContains(t, err, "not an HTML page")
+}
+
+func TestArticleExtractor_Expand(t *testing.T) {
+    server := articleServer(t)
+    defer server.Close()
+    extractor := NewArticleExtractor()
+    ctx := context.Background()
+
+    // A link post gets the article text and the OpenGraph image
+    post := storage.Post{RedditID: "abc", URL: server.URL + "/news/chips"}
+    require.NoError(t, extractor.Expand(ctx, &post))
+    assert.True(t, strings.HasPrefix(post.Body, "A bet on inference"))
+    assert.Equal(t, []string{server.URL + "/images/chip-hero.jpg"}, post.MediaURLs)
+
+    // Its own media and text win
+    post = storage.Post{RedditID: "def", URL: server.URL + "/news/chips", MediaURLs: []string{"https://i.redd.it/own.png"}}
+    require.NoError(t, extractor.Expand(ctx, &post))
+    assert.Equal(t, []string{"https://i.redd.it/own.png"}, post.MediaURLs)
+
+    post = storage.Post{RedditID: "ghi", URL: server.URL + "/missing", Body: "Self text"}
+    require.NoError(t, extractor.Expand(ctx, &post))
+    assert.Equal(t, "Self text", post.Body)
+
+    // Posts without a link are left alone
+    post = storage.Post{RedditID: "jkl"}
+    require.NoError(t, extractor.Expand(ctx, &post))
+    assert.Empty(t, post.Body)
+}
+
+func TestTruncateText(t *testing.T) {
+    assert.Equal(t, "short", truncateText("short", 10))
+
+    text := strings.Repeat("a", 60) + "\n\n" + strings.Repeat("b", 60)
+    assert.Equal(t, strings.Repeat("a", 60), truncateText(text, 100))
+    assert.Equal(t, strings.Repeat("a", 10)+"…", truncateText(strings.Repeat("a", 20), 10))
+}
+
+func TestRedditPostData_LinkURL(t *testing.T) {
+    tests := []struct {
+        data redditPostData
+        want string
+    }{
+        {redditPostData{URL: "https://techdaily.example.com/news/chips"}, "https://techdaily.example.com/news/chips"},
+        {redditPostData{URL: "https://www.reddit.com/r/ml/comments/abc/", IsSelf: true}, ""},
+        {redditPostData{URL: "https://i.redd.it/q7z4m2.png", PostHint: "image"}, ""},
+        {redditPostData{URL: "https://v.redd.it/k3n8x1", IsVideo: true}, ""},
+        {redditPostData{URL: "https://www.reddit.com/gallery/xyz"}, ""},
+        {redditPostData{URL: "/r/ml/comments/abc/"}, ""},
+    }
+    for _, tt := range tests {
+        assert.Equal(t, tt.want, tt.data.linkURL(), tt.data.URL)
+    }
+}
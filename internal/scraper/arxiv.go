@@ -0,0 +1,171 @@
+package scraper
+
+import (
+    "context"
+    "encoding/xml"
+    "fmt"
+    "io"
+    "net/http"
+    "regexp"
+    "strings"
+
+    "github.com/w1zzzle/ai-newsbot/internal/config"
+    "github.com/w1zzzle/ai-newsbot/internal/storage"
+)
+
+// ArxivScraper reads new papers from the arXiv Atom API. A source's URL is
+// an API query such as
+// http://export.arxiv.org/api/query?search_query=cat:cs.AI&sortBy=submittedDate
+type ArxivScraper struct {
+    sources []config.Source
+    http    *fetcher
+}
+
+// arxivFeed mirrors the parts of an arXiv API response we use
+type arxivFeed struct {
+    Entries []arxivEntry `xml:"entry"`
+}
+
+type arxivEntry struct {
+    ID        string     `xml:"id"`
+    Title     string     `xml:"title"`
+    Summary   string     `xml:"summary"`
+    Published string     `xml:"published"`
+    Updated   string     `xml:"updated"`
+    Links     []atomLink `xml:"link"`
+    Authors   []struct {
+        Name string `xml:"name"`
+    } `xml:"author"`
+    Categories []struct {
+        Term string `xml:"term,attr"`
+    } `xml:"category"`
+}
+
+// arxivVersion matches the version suffix of IDs such as 2401.01234v2
+var arxivVersion = regexp.MustCompile(`v\d+$`)
+
+// NewArxiv creates a scraper for arXiv API queries
+func NewArxiv(sources []config.Source, opts ...Option) *ArxivScraper {
+    return &ArxivScraper{
+        sources: sources,
+        http:    newFetcher(opts...),
+    }
+}
+
+func (s *ArxivScraper) FetchPosts(ctx context.Context) ([]storage.Post, error) {
+    return collectPosts(s.FetchResults(ctx))
+}
+
+func (s *ArxivScraper) FetchResults(ctx context.Context) []SourceResult {
+    return s.http.fetchConcurrently(ctx, s.sources, func(ctx context.Context, source config.Source) SourceResult {
+        posts, err := s.fetchPapers(ctx, source)
+        return sourceResult(source, posts, err)
+    })
+}
+
+func (s *ArxivScraper) fetchPapers(ctx context.Context, source config.Source) ([]storage.Post, error) {
+    resp, err := s.http.get(ctx, source.URL, http.Header{"Accept": {"application/atom+xml"}})
+    if err != nil {
+        return nil, err
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode != http.StatusOK {
+        return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
+    }
+
+    data, err := io.ReadAll(resp.Body)
+    if err != nil {
+        return nil, err
+    }
+
+    var feed arxivFeed
+    if err := xml.Unmarshal(data, &feed); err != nil {
+        return nil, fmt.Errorf("failed to decode arXiv response: %w", err)
+    }
+
+    var posts []storage.Post
+    for _, entry := range feed.Entries {
+        if !entry.inCategories(source.Categories) {
+            continue
+        }
+        post := entry.toPost()
+        if post == nil || !matchesKeywords(*post, source.Keywords) {
+            continue
+        }
+        posts = append(posts, *post)
+    }
+
+    s.http.remember(ctx, resp)
+    return posts, nil
+}
+
+// inCategories reports whether the paper is listed in any of the
+// categories. An empty list matches every paper.
+func (entry arxivEntry) inCategories(categories []string) bool {
+    if len(categories) == 0 {
+        return true
+    }
+    for _, c := range entry.Categories {
+        for _, want := range categories {
+            if strings.EqualFold(c.Term, strings.TrimSpace(want)) {
+                return true
+            }
+        }
+    }
+    return false
+}
+
+func (entry arxivEntry) toPost() *storage.Post {
+    id := arxivID(entry.ID)
+    title := collapseSpace(entry.Title)
+    if id == "" || title == "" {
+        return nil
+    }
+
+    var pdf string
+    for _, link := range entry.Links {
+        if link.Title == "pdf" || link.Type == "application/pdf" {
+            pdf = link.Href
+            break
+        }
+    }
+
+    var authors []string
+    for _, author := range entry.Authors {
+        if name := strings.TrimSpace(author.Name); name != "" {
+            authors = append(authors, name)
+        }
+    }
+
+    return &storage.Post{
+        RedditID:  arxivPostID(id),
+        Title:     title,
+        Body:      collapseSpace(entry.Summary),
+        Permalink: "https://arxiv.org/abs/" + id,
+        URL:       pdf,
+        Author:    strings.Join(authors, ", "),
+        CreatedAt: parseFeedTime(entry.Published),
+    }
+}
+
+// arxivID extracts the version-less paper ID from an entry ID such as
+// http://arxiv.org/abs/2401.01234v2 or http://arxiv.org/abs/cs/0112017v1
+func arxivID(entryID string) string {
+    entryID = strings.TrimSpace(entryID)
+    if i := strings.Index(entryID, "/abs/"); i >= 0 {
+        entryID = entryID[i+len("/abs/"):]
+    }
+    return arxivVersion.ReplaceAllString(entryID, "")
+}
+
+// arxivPostID namespaces paper IDs. Every version of a paper maps to the
+// same ID, so a revision isn't published again.
+func arxivPostID(id string) string {
+    return "arxiv:" + id
+}
+
+// collapseSpace joins the hard-wrapped lines of arXiv titles and abstracts
+func collapseSpace(text string) string {
+    return strings.Join(strings.Fields(text), " ")
+}
@@ -0,0 +1,67 @@
+package scraper
+
+import (
+    "context"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "github.com/w1zzzle/ai-newsbot/internal/config"
+)
+
+func arxivServer(t *testing.T) *httptest.Server {
+    t.Helper()
+    data := readFixture(t, "arxiv_query.xml")
+    return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.Header().Set("Content-Type", "application/atom+xml")
+        w.Write(data)
+    }))
+}
+
+func TestArxivScraper_FetchPosts(t *testing.T) {
+    server := arxivServer(t)
+    defer server.Close()
+
+    scraper := NewArxiv([]config.Source{
+        {Type: config.SourceArxiv, URL: server.URL + "/api/query?search_query=cat:cs.AI"},
+    })
+    posts, err := scraper.FetchPosts(context.Background())
+    require.NoError(t, err)
+    require.Len(t, posts, 3)
+
+    assert.Equal(t, "arxiv:2401.01234", posts[0].RedditID)
+    assert.Equal(t, "Sparse Mixture-of-Experts Language Models Are Efficient Reasoners", posts[0].Title)
+    assert.Equal(t, "We study sparse mixture-of-experts language models on multi-step reasoning benchmarks and find that they match dense models at a fraction of the compute.", posts[0].Body)
+    assert.Equal(t, "Ada Lovelace, Alan Turing", posts[0].Author)
+    assert.Equal(t, "https://arxiv.org/abs/2401.01234", posts[0].Permalink)
+    assert.Equal(t, "http://arxiv.org/pdf/2401.01234v2", posts[0].URL)
+    assert.Equal(t, time.Date(2024, 1, 3, 18, 59, 59, 0, time.UTC), posts[0].CreatedAt)
+
+    assert.Equal(t, "arxiv:cs/0112017", posts[2].RedditID)
+}
+
+func TestArxivScraper_Filters(t *testing.T) {
+    server := arxivServer(t)
+    defer server.Close()
+
+    scraper := NewArxiv([]config.Source{
+        {Type: config.SourceArxiv, URL: server.URL, Categories: []string{"cs.AI", "cs.CL"}},
+        {Type: config.SourceArxiv, URL: server.URL, Categories: []string{"cs.LG"}, Keywords: []string{"protein"}},
+    })
+    results := scraper.FetchResults(context.Background())
+    require.Len(t, results, 2)
+
+    assert.Equal(t, []string{"arxiv:2401.01234", "arxiv:cs/0112017"}, postIDs(results[0].Posts))
+    assert.Equal(t, []string{"arxiv:2401.01300"}, postIDs(results[1].Posts))
+}
+
+func TestArxivID(t *testing.T) {
+    // Every version of a paper maps to the same ID
+    assert.Equal(t, "2401.01234", arxivID("http://arxiv.org/abs/2401.01234v1"))
+    assert.Equal(t, "2401.01234", arxivID("http://arxiv.org/abs/2401.01234v12"))
+    assert.Equal(t, "2401.01234", arxivID("2401.01234"))
+    assert.Equal(t, "math/0601001", arxivID("http://arxiv.org/abs/math/0601001v3"))
+}
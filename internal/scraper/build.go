@@ -0,0 +1,71 @@
+package scraper
+
+import (
+    "time"
+
+    "github.com/w1zzzle/ai-newsbot/internal/config"
+)
+
+// FromConfig builds the default scraper for the configured sources. seen
+// may be nil; extra options, such as WithValidatorStore, are applied after
+// the configured ones.
+func FromConfig(cfg *config.Config, seen SeenChecker, opts ...Option) Scraper {
+    opts = append([]Option{
+        WithWorkers(cfg.FetchWorkers),
+        WithHostRateLimit(cfg.HostRequestsPerMinute, cfg.HostBurst),
+        WithUserAgent(cfg.ScraperUserAgent),
+        WithTimeout(time.Duration(cfg.ScraperTimeoutSeconds) * time.Second),
+    }, opts...)
+    if cfg.FixtureMode != "" {
+        opts = append(opts, WithFixtures(cfg.FixtureMode, cfg.FixtureDir))
+    }
+
+    byType := make(map[string][]config.Source)
+    for _, source := range cfg.Sources {
+        byType[source.Type] = append(byType[source.Type], source)
+    }
+
+    var scrapers []Scraper
+    if reddit := byType[config.SourceReddit]; len(reddit) > 0 {
+        scrapers = append(scrapers, redditFromConfig(cfg, reddit, seen, opts))
+    }
+    if feeds := byType[config.SourceFeed]; len(feeds) > 0 {
+        scrapers = append(scrapers, NewFeed(feeds, opts...))
+    }
+    if stories := byType[config.SourceHackerNews]; len(stories) > 0 {
+        scrapers = append(scrapers, NewHackerNews(stories, opts...).WithSeenChecker(seen))
+    }
+    if papers := byType[config.SourceArxiv]; len(papers) > 0 {
+        scrapers = append(scrapers, NewArxiv(papers, opts...))
+    }
+    if repos := byType[config.SourceGitHub]; len(repos) > 0 {
+        scrapers = append(scrapers, NewGitHub(repos, GitHubConfig{
+            APIBaseURL: cfg.GitHubAPIURL,
+            Token:      cfg.GitHubToken,
+        }, opts...))
+    }
+    if pages := byType[config.SourceHTML]; len(pages) > 0 {
+        scrapers = append(scrapers, NewHTML(pages, opts...))
+    }
+
+    if len(scrapers) == 1 {
+        return scrapers[0]
+    }
+    return NewMulti(scrapers...)
+}
+
+func redditFromConfig(cfg *config.Config, sources []config.Source, seen SeenChecker, opts []Option) Scraper {
+    if cfg.RedditClientID != "" {
+        s := NewOAuth(sources, OAuthConfig{
+            ClientID:     cfg.RedditClientID,
+            ClientSecret: cfg.RedditClientSecret,
+            Username:     cfg.RedditUsername,
+            Password:     cfg.RedditPassword,
+            UserAgent:    cfg.RedditUserAgent,
+        }, opts...)
+        s.WithSeenChecker(seen)
+        return s
+    }
+
+    return NewJSONFromSources(sources, opts...).WithSeenChecker(seen)
+}
@@ -0,0 +1,162 @@
+package scraper
+
+import (
+    "context"
+    "errors"
+    "log"
+    "net/http"
+    "sync"
+
+    "github.com/w1zzzle/ai-newsbot/internal/storage"
+)
+
+// errNotModified is returned by fetchers when the server answered 304, so
+// the source has nothing new since the last run
+var errNotModified = errors.New("not modified")
+
+// ValidatorStore persists HTTP cache validators between restarts.
+// storage.PostgresStore implements it.
+type ValidatorStore interface {
+    GetHTTPValidators(ctx context.Context, url string) (storage.HTTPValidators, bool, error)
+    SaveHTTPValidators(ctx context.Context, v storage.HTTPValidators) error
+}
+
+// WithValidatorStore keeps ETag/Last-Modified values in store as well as
+// in memory
+func WithValidatorStore(store ValidatorStore) Option {
+    return func(f *fetcher) {
+        f.validators.store = store
+    }
+}
+
+// validatorCache remembers the ETag and Last-Modified values returned for
+// each URL, backed by an optional persistent store
+type validatorCache struct {
+    mu      sync.Mutex
+    entries map[string]storage.HTTPValidators
+    store   ValidatorStore
+}
+
+func newValidatorCache() *validatorCache {
+    return &validatorCache{
+        entries: make(map[string]storage.HTTPValidators),
+    }
+}
+
+func (c *validatorCache) get(ctx context.Context, url string) (storage.HTTPValidators, bool) {
+    c.mu.Lock()
+    v, ok := c.entries[url]
+    c.mu.Unlock()
+    if ok || c.store == nil {
+        return v, ok
+    }
+
+    v, ok, err := c.store.GetHTTPValidators(ctx, url)
+    if err != nil {
+        log.Printf("Failed to load cache validators for %s: %v", url, err)
+        return storage.HTTPValidators{}, false
+    }
+    if ok {
+        c.mu.Lock()
+        c.entries[url] = v
+        c.mu.Unlock()
+    }
+
+    return v, ok
+}
+
+func (c *validatorCache) set(ctx context.Context, v storage.HTTPValidators) {
+    c.mu.Lock()
+    c.entries[v.URL] = v
+    c.mu.Unlock()
+
+    if c.store != nil {
+        if err := c.store.SaveHTTPValidators(ctx, v); err != nil {
+            log.Printf("Failed to save cache validators for %s: %v", v.URL, err)
+        }
+    }
+}
+
+// addConditionalHeaders sets If-None-Match/If-Modified-Since from the
+// validators last stored for the request URL
+func (c *validatorCache) addConditionalHeaders(req *http.Request) {
+    v, ok := c.get(req.Context(), req.URL.String())
+    if !ok {
+        return
+    }
+    if v.ETag != "" {
+        req.Header.Set("If-None-Match", v.ETag)
+    }
+    if v.LastModified != "" {
+        req.Header.Set("If-Modified-Since", v.LastModified)
+    }
+}
+
+// remember notes the validators of a successfully processed response.
+// Callers only do so after parsing, so a page that failed to parse is
+// downloaded again on the next run. Responses fetched for a source are held
+// back until its result is committed.
+func (f *fetcher) remember(ctx context.Context, resp *http.Response) {
+    etag := resp.Header.Get("ETag")
+    lastModified := resp.Header.Get("Last-Modified")
+    if etag == "" && lastModified == "" {
+        return
+    }
+
+    v := storage.HTTPValidators{
+        URL:          requestedURL(resp),
+        ETag:         etag,
+        LastModified: lastModified,
+    }
+    if pending, ok := ctx.Value(pendingKey{}).(*pendingValidators); ok {
+        pending.add(f.validators, v)
+        return
+    }
+    f.validators.set(ctx, v)
+}
+
+// requestedURL returns the URL the caller asked for, before any redirects,
+// which is the one addConditionalHeaders looks validators up by
+func requestedURL(resp *http.Response) string {
+    req := resp.Request
+    for req.Response != nil && req.Response.Request != nil {
+        req = req.Response.Request
+    }
+    return req.URL.String()
+}
+
+type pendingKey struct{}
+
+// pendingValidators holds the validators of one source's responses until
+// the pipeline has saved the source's posts
+type pendingValidators struct {
+    mu      sync.Mutex
+    entries []pendingValidator
+}
+
+type pendingValidator struct {
+    cache      *validatorCache
+    validators storage.HTTPValidators
+}
+
+// withPendingValidators makes remember hold validators in pending
+func withPendingValidators(ctx context.Context, pending *pendingValidators) context.Context {
+    return context.WithValue(ctx, pendingKey{}, pending)
+}
+
+func (p *pendingValidators) add(cache *validatorCache, v storage.HTTPValidators) {
+    p.mu.Lock()
+    defer p.mu.Unlock()
+    p.entries = append(p.entries, pendingValidator{cache: cache, validators: v})
+}
+
+func (p *pendingValidators) commit(ctx context.Context) {
+    p.mu.Lock()
+    entries := p.entries
+    p.entries = nil
+    p.mu.Unlock()
+
+    for _, entry := range entries {
+        entry.cache.set(ctx, entry.validators)
+    }
+}
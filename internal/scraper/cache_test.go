@@ -0,0 +1,159 @@
+package scraper
+
+import (
+    "context"
+    "net/http"
+    "net/http/httptest"
+    "os"
+    "path/filepath"
+    "sync/atomic"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "github.com/w1zzzle/ai-newsbot/internal/storage"
+)
+
+// mockValidatorStore stands in for the Postgres http_cache table
+type mockValidatorStore struct {
+    entries map[string]storage.HTTPValidators
+}
+
+func (m *mockValidatorStore) GetHTTPValidators(ctx context.Context, url string) (storage.HTTPValidators, bool, error) {
+    v, ok := m.entries[url]
+    return v, ok, nil
+}
+
+func (m *mockValidatorStore) SaveHTTPValidators(ctx context.Context, v storage.HTTPValidators) error {
+    m.entries[v.URL] = v
+    return nil
+}
+
+// conditionalServer serves the listing fixture with an ETag and answers 304
+// when the client already has it
+func conditionalServer(t *testing.T, parsed *int32) *httptest.Server {
+    t.Helper()
+    data, err := os.ReadFile(filepath.Join("testdata", "listing_top.json"))
+    require.NoError(t, err)
+
+    return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if r.Header.Get("If-None-Match") == `"v1"` {
+            w.WriteHeader(http.StatusNotModified)
+            return
+        }
+        atomic.AddInt32(parsed, 1)
+        w.Header().Set("ETag", `"v1"`)
+        w.Header().Set("Last-Modified", "Mon, 11 Mar 2024 12:00:00 GMT")
+        w.WriteHeader(http.StatusOK)
+        w.Write(data)
+    }))
+}
+
+func TestJSONScraper_ConditionalGet(t *testing.T) {
+    var fullResponses int32
+    server := conditionalServer(t, &fullResponses)
+    defer server.Close()
+
+    scraper := NewJSON([]string{server.URL + "/r/MachineLearning/top/"}, 100)
+
+    results := scraper.FetchResults(context.Background())
+    require.Len(t, results, 1)
+    require.NoError(t, results[0].Err)
+    assert.False(t, results[0].NotModified)
+    assert.Len(t, results[0].Posts, 2)
+    results[0].CommitCache(context.Background())
+
+    // The second run gets a 304 and reports no new posts
+    results = scraper.FetchResults(context.Background())
+    require.Len(t, results, 1)
+    require.NoError(t, results[0].Err)
+    assert.True(t, results[0].NotModified)
+    assert.Empty(t, results[0].Posts)
+    assert.Equal(t, int32(1), atomic.LoadInt32(&fullResponses))
+}
+
+func TestJSONScraper_ConditionalGet_PersistentStore(t *testing.T) {
+    var fullResponses int32
+    server := conditionalServer(t, &fullResponses)
+    defer server.Close()
+
+    store := &mockValidatorStore{entries: make(map[string]storage.HTTPValidators)}
+    urls := []string{server.URL + "/r/MachineLearning/top/"}
+
+    results := NewJSON(urls, 100, WithValidatorStore(store)).FetchResults(context.Background())
+    require.Len(t, results, 1)
+    require.NoError(t, results[0].Err)
+    assert.Empty(t, store.entries)
+    results[0].CommitCache(context.Background())
+
+    v, ok := store.entries[server.URL+"/r/MachineLearning/top.json"]
+    require.True(t, ok)
+    assert.Equal(t, `"v1"`, v.ETag)
+    assert.Equal(t, "Mon, 11 Mar 2024 12:00:00 GMT", v.LastModified)
+
+    // A fresh scraper, as after a restart, picks the validators up from the store
+    results = NewJSON(urls, 100, WithValidatorStore(store)).FetchResults(context.Background())
+    require.Len(t, results, 1)
+    assert.True(t, results[0].NotModified)
+    assert.Equal(t, int32(1), atomic.LoadInt32(&fullResponses))
+}
+
+func TestJSONScraper_ConditionalGet_NotRememberedUntilCommitted(t *testing.T) {
+    var fullResponses int32
+    server := conditionalServer(t, &fullResponses)
+    defer server.Close()
+
+    scraper := NewJSON([]string{server.URL + "/r/MachineLearning/top/"}, 100)
+    scraper.FetchResults(context.Background())
+
+    // The posts of the first run were never saved, so they are served again
+    results := scraper.FetchResults(context.Background())
+    require.Len(t, results, 1)
+    assert.False(t, results[0].NotModified)
+    assert.Len(t, results[0].Posts, 2)
+    assert.Equal(t, int32(2), atomic.LoadInt32(&fullResponses))
+}
+
+func TestFetcher_Remember_KeysByRequestedURL(t *testing.T) {
+    store := &mockValidatorStore{entries: make(map[string]storage.HTTPValidators)}
+    mux := http.NewServeMux()
+    mux.Handle("/old/top.json", http.RedirectHandler("/r/MachineLearning/top.json", http.StatusMovedPermanently))
+    mux.HandleFunc("/r/MachineLearning/top.json", func(w http.ResponseWriter, r *http.Request) {
+        w.Header().Set("ETag", `"v1"`)
+    })
+    server := httptest.NewServer(mux)
+    defer server.Close()
+
+    f := newFetcher(WithValidatorStore(store))
+    resp, err := f.client.Get(server.URL + "/old/top.json")
+    require.NoError(t, err)
+    resp.Body.Close()
+    f.remember(context.Background(), resp)
+
+    // The next run asks for the original URL, so that is what it is stored by
+    assert.Contains(t, store.entries, server.URL+"/old/top.json")
+    assert.NotContains(t, store.entries, server.URL+"/r/MachineLearning/top.json")
+}
+
+func TestJSONScraper_ConditionalGet_NotRememberedOnParseFailure(t *testing.T) {
+    var listingCalls int32
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if r.URL.Path != "/r/OpenAI/top.json" {
+            w.WriteHeader(http.StatusNotFound)
+            return
+        }
+        atomic.AddInt32(&listingCalls, 1)
+        assert.Empty(t, r.Header.Get("If-None-Match"))
+        w.Header().Set("ETag", `"broken"`)
+        w.WriteHeader(http.StatusOK)
+        w.Write([]byte(`{"not": "a listing"}`))
+    }))
+    defer server.Close()
+
+    scraper := NewJSON([]string{server.URL + "/r/OpenAI/top/"}, 100)
+    scraper.FetchResults(context.Background())
+    scraper.FetchResults(context.Background())
+
+    // The listing failed to parse, so the second run downloads it again
+    assert.Equal(t, int32(2), atomic.LoadInt32(&listingCalls))
+}
@@ -0,0 +1,158 @@
+package scraper
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "net/url"
+    "sort"
+    "strconv"
+    "strings"
+
+    "github.com/w1zzzle/ai-newsbot/internal/storage"
+)
+
+// CommentFetcher is implemented by scrapers that can load the discussion
+// of a post they produced
+type CommentFetcher interface {
+    // FetchComments returns up to k top comments of post. Posts the
+    // scraper doesn't know yield no comments and no error.
+    FetchComments(ctx context.Context, post storage.Post, k int) ([]storage.Comment, error)
+}
+
+// redditCommentListing mirrors the comment half of a comments response
+type redditCommentListing struct {
+    Data struct {
+        Children []struct {
+            Kind string            `json:"kind"`
+            Data redditCommentData `json:"data"`
+        } `json:"children"`
+    } `json:"data"`
+}
+
+type redditCommentData struct {
+    Author   string `json:"author"`
+    Body     string `json:"body"`
+    Score    int    `json:"score"`
+    Stickied bool   `json:"stickied"`
+    // Distinguished is "moderator" or "admin" for official comments
+    Distinguished string `json:"distinguished"`
+}
+
+// FetchComments loads the top-level comments of a Reddit post and returns
+// the k highest-scoring ones, leaving out stickied and moderator comments
+func (s *JSONScraper) FetchComments(ctx context.Context, post storage.Post, k int) ([]storage.Comment, error) {
+    // Namespaced IDs belong to other sources
+    if k <= 0 || strings.Contains(post.RedditID, ":") {
+        return nil, nil
+    }
+
+    commentsURL, err := s.commentsURL(post, k)
+    if err != nil {
+        return nil, err
+    }
+
+    resp, err := s.http.get(ctx, commentsURL, http.Header{"Accept": {"application/json"}})
+    if err != nil {
+        return nil, err
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode != http.StatusOK {
+        return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
+    }
+
+    // The response holds the post's listing followed by its comments
+    var listings []json.RawMessage
+    if err := json.NewDecoder(resp.Body).Decode(&listings); err != nil {
+        return nil, fmt.Errorf("failed to decode comments: %w", err)
+    }
+    if len(listings) < 2 {
+        return nil, fmt.Errorf("comments response has %d listings", len(listings))
+    }
+
+    var listing redditCommentListing
+    if err := json.Unmarshal(listings[1], &listing); err != nil {
+        return nil, fmt.Errorf("failed to decode comments: %w", err)
+    }
+
+    var comments []redditCommentData
+    for _, child := range listing.Data.Children {
+        // t1 is a comment; "more" stubs hold no text
+        if child.Kind == "t1" {
+            comments = append(comments, child.Data)
+        }
+    }
+    return topComments(comments, k), nil
+}
+
+// commentsURL returns the JSON comments endpoint of a post, asking for
+// more top-level comments than needed so filtered ones can be skipped
+func (s *JSONScraper) commentsURL(post storage.Post, k int) (string, error) {
+    page := post.Permalink
+    if page == "" {
+        page = redditBaseURL + "/comments/" + post.RedditID + "/"
+    }
+
+    commentsURL, err := jsonListingURL(page)
+    if err != nil {
+        return "", err
+    }
+    if s.apiBase != "" {
+        if commentsURL, err = rebaseURL(commentsURL, s.apiBase); err != nil {
+            return "", err
+        }
+    }
+
+    u, err := url.Parse(commentsURL)
+    if err != nil {
+        return "", err
+    }
+    q := u.Query()
+    q.Set("sort", "top")
+    q.Set("depth", "1")
+    q.Set("limit", strconv.Itoa(max(4*k, 20)))
+    u.RawQuery = q.Encode()
+
+    return u.String(), nil
+}
+
+// topComments picks the k highest-scoring comments written by regular
+// users
+func topComments(comments []redditCommentData, k int) []storage.Comment {
+    var kept []storage.Comment
+    for _, c := range comments {
+        body := strings.TrimSpace(c.Body)
+        if c.Stickied || c.Distinguished != "" || c.Author == "AutoModerator" {
+            continue
+        }
+        if body == "" || body == "[deleted]" || body == "[removed]" {
+            continue
+        }
+        kept = append(kept, storage.Comment{Author: c.Author, Body: body, Score: c.Score})
+    }
+
+    sort.SliceStable(kept, func(i, j int) bool {
+        return kept[i].Score > kept[j].Score
+    })
+    if len(kept) > k {
+        kept = kept[:k]
+    }
+    return kept
+}
+
+// FetchComments asks each scraper in turn for the post's comments
+func (m *Multi) FetchComments(ctx context.Context, post storage.Post, k int) ([]storage.Comment, error) {
+    for _, s := range m.scrapers {
+        fetcher, ok := s.(CommentFetcher)
+        if !ok {
+            continue
+        }
+        comments, err := fetcher.FetchComments(ctx, post, k)
+        if err != nil || len(comments) > 0 {
+            return comments, err
+        }
+    }
+    return nil, nil
+}
@@ -0,0 +1,70 @@
+package scraper
+
+import (
+    "context"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "github.com/w1zzzle/ai-newsbot/internal/storage"
+)
+
+func TestJSONScraper_FetchComments(t *testing.T) {
+    var requested *http.Request
+    fixture := serveFixture(t, "comments_top.json")
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        requested = r
+        fixture(w, r)
+    }))
+    defer server.Close()
+
+    post := storage.Post{
+        RedditID:  "1b9xq2a",
+        Permalink: server.URL + "/r/MachineLearning/comments/1b9xq2a/r_open_7b_model/",
+    }
+    comments, err := NewJSON(nil, 0).FetchComments(context.Background(), post, 3)
+    require.NoError(t, err)
+
+    assert.Equal(t, "/r/MachineLearning/comments/1b9xq2a/r_open_7b_model.json", requested.URL.Path)
+    assert.Equal(t, "top", requested.URL.Query().Get("sort"))
+    assert.Equal(t, "1", requested.URL.Query().Get("depth"))
+
+    // Stickied, moderator and deleted comments are skipped
+    require.Len(t, comments, 3)
+    assert.Equal(t, storage.Comment{Author: "gpu_poor", Body: "Finally something I can run on a 3090.", Score: 845}, comments[0])
+    assert.Equal(t, "researcher", comments[1].Author)
+    assert.Equal(t, "The ablations in section 4 are the interesting part.", comments[1].Body)
+    assert.Equal(t, "skeptic42", comments[2].Author)
+}
+
+func TestJSONScraper_FetchComments_OtherSources(t *testing.T) {
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        t.Errorf("unexpected request to %s", r.URL)
+    }))
+    defer server.Close()
+
+    scraper := NewJSON(nil, 0)
+    comments, err := scraper.FetchComments(context.Background(), storage.Post{RedditID: "hn:101", Permalink: server.URL}, 3)
+    require.NoError(t, err)
+    assert.Empty(t, comments)
+
+    comments, err = scraper.FetchComments(context.Background(), storage.Post{RedditID: "abc", Permalink: server.URL + "/r/x/comments/abc/"}, 0)
+    require.NoError(t, err)
+    assert.Empty(t, comments)
+}
+
+func TestMulti_FetchComments(t *testing.T) {
+    server := httptest.NewServer(serveFixture(t, "comments_top.json"))
+    defer server.Close()
+
+    multi := NewMulti(NewFeed(nil), NewJSON(nil, 0))
+    comments, err := multi.FetchComments(context.Background(), storage.Post{
+        RedditID:  "1b9xq2a",
+        Permalink: server.URL + "/r/MachineLearning/comments/1b9xq2a/",
+    }, 1)
+    require.NoError(t, err)
+    require.Len(t, comments, 1)
+    assert.Equal(t, "gpu_poor", comments[0].Author)
+}
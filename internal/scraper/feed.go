@@ -0,0 +1,360 @@
+package scraper
+
+import (
+    "bytes"
+    "context"
+    "crypto/sha1"
+    "encoding/hex"
+    "encoding/xml"
+    "fmt"
+    "io"
+    "net/http"
+    "regexp"
+    "strings"
+    "time"
+
+    "github.com/PuerkitoBio/goquery"
+    "github.com/w1zzzle/ai-newsbot/internal/config"
+    "github.com/w1zzzle/ai-newsbot/internal/storage"
+)
+
+// FeedScraper reads RSS 2.0 and Atom feeds, such as company blogs and
+// newsletters
+type FeedScraper struct {
+    sources []config.Source
+    http    *fetcher
+}
+
+// rssFeed mirrors the parts of an RSS 2.0 document we use
+type rssFeed struct {
+    Channel struct {
+        Title string    `xml:"title"`
+        Items []rssItem `xml:"item"`
+    } `xml:"channel"`
+}
+
+type rssItem struct {
+    Title       string `xml:"title"`
+    Link        string `xml:"link"`
+    Description string `xml:"description"`
+    // content:encoded carries the full post in many feeds
+    Content    string `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+    GUID       string `xml:"guid"`
+    PubDate    string `xml:"pubDate"`
+    Author     string `xml:"author"`
+    Creator    string `xml:"http://purl.org/dc/elements/1.1/ creator"`
+    Enclosures []struct {
+        URL  string `xml:"url,attr"`
+        Type string `xml:"type,attr"`
+    } `xml:"enclosure"`
+    MediaContent []struct {
+        URL string `xml:"url,attr"`
+    } `xml:"http://search.yahoo.com/mrss/ content"`
+}
+
+// atomFeed mirrors the parts of an Atom document we use
+type atomFeed struct {
+    Title   string      `xml:"title"`
+    Entries []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+    ID        string     `xml:"id"`
+    Title     atomText   `xml:"title"`
+    Summary   atomText   `xml:"summary"`
+    Content   atomText   `xml:"content"`
+    Published string     `xml:"published"`
+    Updated   string     `xml:"updated"`
+    Links     []atomLink `xml:"link"`
+    Authors   []struct {
+        Name string `xml:"name"`
+    } `xml:"author"`
+}
+
+// atomText is an Atom text construct, which holds plain text, escaped HTML
+// or inline XHTML depending on its type
+type atomText struct {
+    Type  string `xml:"type,attr"`
+    Text  string `xml:",chardata"`
+    Inner string `xml:",innerxml"`
+}
+
+func (t atomText) html() string {
+    if t.Type == "xhtml" {
+        return t.Inner
+    }
+    return t.Text
+}
+
+type atomLink struct {
+    Href  string `xml:"href,attr"`
+    Rel   string `xml:"rel,attr"`
+    Type  string `xml:"type,attr"`
+    Title string `xml:"title,attr"`
+}
+
+// NewFeed creates a scraper for RSS and Atom feed sources
+func NewFeed(sources []config.Source, opts ...Option) *FeedScraper {
+    return &FeedScraper{
+        sources: sources,
+        http:    newFetcher(opts...),
+    }
+}
+
+func (s *FeedScraper) FetchPosts(ctx context.Context) ([]storage.Post, error) {
+    return collectPosts(s.FetchResults(ctx))
+}
+
+func (s *FeedScraper) FetchResults(ctx context.Context) []SourceResult {
+    return s.http.fetchConcurrently(ctx, s.sources, func(ctx context.Context, source config.Source) SourceResult {
+        posts, err := s.fetchFeed(ctx, source.URL)
+        return sourceResult(source, posts, err)
+    })
+}
+
+func (s *FeedScraper) fetchFeed(ctx context.Context, url string) ([]storage.Post, error) {
+    resp, err := s.http.get(ctx, url, http.Header{
+        "Accept": {"application/rss+xml, application/atom+xml, application/xml;q=0.9, */*;q=0.8"},
+    })
+    if err != nil {
+        return nil, err
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode != http.StatusOK {
+        return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
+    }
+
+    data, err := io.ReadAll(resp.Body)
+    if err != nil {
+        return nil, err
+    }
+
+    posts, err := parseFeed(data)
+    if err != nil {
+        return nil, err
+    }
+
+    s.http.remember(ctx, resp)
+    return posts, nil
+}
+
+// parseFeed detects whether data is RSS or Atom and maps its items to posts
+func parseFeed(data []byte) ([]storage.Post, error) {
+    root, err := feedRoot(data)
+    if err != nil {
+        return nil, err
+    }
+
+    switch root {
+    case "rss":
+        var feed rssFeed
+        if err := decodeXML(data, &feed); err != nil {
+            return nil, fmt.Errorf("failed to decode RSS feed: %w", err)
+        }
+        posts := make([]storage.Post, 0, len(feed.Channel.Items))
+        for _, item := range feed.Channel.Items {
+            if post := item.toPost(); post != nil {
+                posts = append(posts, *post)
+            }
+        }
+        return posts, nil
+    case "feed":
+        var feed atomFeed
+        if err := decodeXML(data, &feed); err != nil {
+            return nil, fmt.Errorf("failed to decode Atom feed: %w", err)
+        }
+        posts := make([]storage.Post, 0, len(feed.Entries))
+        for _, entry := range feed.Entries {
+            if post := entry.toPost(); post != nil {
+                posts = append(posts, *post)
+            }
+        }
+        return posts, nil
+    default:
+        return nil, fmt.Errorf("unsupported feed format <%s>", root)
+    }
+}
+
+// decodeXML decodes leniently: real-world feeds often contain HTML
+// entities such as &nbsp; that strict XML rejects
+func decodeXML(data []byte, v any) error {
+    decoder := xml.NewDecoder(bytes.NewReader(data))
+    decoder.Strict = false
+    decoder.Entity = xml.HTMLEntity
+    return decoder.Decode(v)
+}
+
+// feedRoot returns the local name of the document's root element
+func feedRoot(data []byte) (string, error) {
+    decoder := xml.NewDecoder(bytes.NewReader(data))
+    decoder.Strict = false
+    for {
+        token, err := decoder.Token()
+        if err != nil {
+            return "", fmt.Errorf("failed to read feed: %w", err)
+        }
+        if start, ok := token.(xml.StartElement); ok {
+            return start.Name.Local, nil
+        }
+    }
+}
+
+func (item rssItem) toPost() *storage.Post {
+    title := strings.TrimSpace(stripHTML(item.Title))
+    if title == "" {
+        return nil
+    }
+
+    guid := strings.TrimSpace(item.GUID)
+    if guid == "" {
+        guid = strings.TrimSpace(item.Link)
+    }
+    if guid == "" {
+        guid = title + item.PubDate
+    }
+
+    body := item.Content
+    if strings.TrimSpace(body) == "" {
+        body = item.Description
+    }
+
+    author := item.Creator
+    if author == "" {
+        author = item.Author
+    }
+
+    var mediaURLs []string
+    for _, enclosure := range item.Enclosures {
+        if strings.HasPrefix(enclosure.URL, "http") {
+            mediaURLs = append(mediaURLs, enclosure.URL)
+        }
+    }
+    for _, media := range item.MediaContent {
+        if strings.HasPrefix(media.URL, "http") {
+            mediaURLs = append(mediaURLs, media.URL)
+        }
+    }
+
+    return &storage.Post{
+        RedditID:  feedPostID(guid),
+        Title:     title,
+        Body:      stripHTML(body),
+        Permalink: strings.TrimSpace(item.Link),
+        Author:    strings.TrimSpace(author),
+        MediaURLs: mediaURLs,
+        CreatedAt: parseFeedTime(item.PubDate),
+    }
+}
+
+func (entry atomEntry) toPost() *storage.Post {
+    title := strings.TrimSpace(stripHTML(entry.Title.html()))
+    if title == "" {
+        return nil
+    }
+
+    var link string
+    var mediaURLs []string
+    for _, l := range entry.Links {
+        switch l.Rel {
+        case "", "alternate":
+            if link == "" {
+                link = l.Href
+            }
+        case "enclosure":
+            if strings.HasPrefix(l.Href, "http") {
+                mediaURLs = append(mediaURLs, l.Href)
+            }
+        }
+    }
+
+    guid := strings.TrimSpace(entry.ID)
+    if guid == "" {
+        guid = link
+    }
+
+    body := entry.Content.html()
+    if strings.TrimSpace(body) == "" {
+        body = entry.Summary.html()
+    }
+
+    var authors []string
+    for _, author := range entry.Authors {
+        if name := strings.TrimSpace(author.Name); name != "" {
+            authors = append(authors, name)
+        }
+    }
+
+    published := entry.Published
+    if published == "" {
+        published = entry.Updated
+    }
+
+    return &storage.Post{
+        RedditID:  feedPostID(guid),
+        Title:     title,
+        Body:      stripHTML(body),
+        Permalink: link,
+        Author:    strings.Join(authors, ", "),
+        MediaURLs: mediaURLs,
+        CreatedAt: parseFeedTime(published),
+    }
+}
+
+// feedPostID derives a stable ID from an item's GUID. GUIDs are often long
+// URLs, so they are hashed and namespaced to keep clear of Reddit IDs.
+func feedPostID(guid string) string {
+    sum := sha1.Sum([]byte(guid))
+    return "feed:" + hex.EncodeToString(sum[:])[:16]
+}
+
+var feedTimeLayouts = []string{
+    time.RFC3339,
+    time.RFC1123Z,
+    time.RFC1123,
+    "Mon, 2 Jan 2006 15:04:05 -0700",
+    "Mon, 2 Jan 2006 15:04:05 MST",
+    "2 Jan 2006 15:04:05 -0700",
+    time.RFC822Z,
+    time.RFC822,
+}
+
+// parseFeedTime parses RSS and Atom dates, treating unparseable dates as now
+func parseFeedTime(value string) time.Time {
+    value = strings.TrimSpace(value)
+    for _, layout := range feedTimeLayouts {
+        if t, err := time.Parse(layout, value); err == nil {
+            return t.UTC()
+        }
+    }
+    return time.Now()
+}
+
+var (
+    blankLines = regexp.MustCompile(`\n\s*\n+`)
+    spaceRuns  = regexp.MustCompile(`[ \t\r\f\x{00a0}]+`)
+)
+
+// stripHTML turns an HTML fragment into plain text, keeping paragraph breaks
+func stripHTML(fragment string) string {
+    if !strings.Contains(fragment, "<") && !strings.Contains(fragment, "&") {
+        return strings.TrimSpace(fragment)
+    }
+
+    doc, err := goquery.NewDocumentFromReader(strings.NewReader(fragment))
+    if err != nil {
+        return strings.TrimSpace(fragment)
+    }
+
+    doc.Find("script, style").Remove()
+    doc.Find("br").ReplaceWithHtml("\n")
+    doc.Find("p, div, li, h1, h2, h3, h4, h5, h6, blockquote, pre, tr").AppendHtml("\n\n")
+
+    var lines []string
+    for _, line := range strings.Split(doc.Text(), "\n") {
+        lines = append(lines, strings.TrimSpace(spaceRuns.ReplaceAllString(line, " ")))
+    }
+
+    text := blankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+    return strings.TrimSpace(text)
+}
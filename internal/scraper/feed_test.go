@@ -0,0 +1,155 @@
+package scraper
+
+import (
+    "context"
+    "net/http"
+    "net/http/httptest"
+    "os"
+    "path/filepath"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "github.com/w1zzzle/ai-newsbot/internal/config"
+    "github.com/w1zzzle/ai-newsbot/internal/storage"
+)
+
+func readFixture(t *testing.T, name string) []byte {
+    t.Helper()
+    data, err := os.ReadFile(filepath.Join("testdata", name))
+    require.NoError(t, err)
+    return data
+}
+
+func TestParseFeed_RSS(t *testing.T) {
+    posts, err := parseFeed(readFixture(t, "feed_rss.xml"))
+    require.NoError(t, err)
+    require.Len(t, posts, 2)
+
+    assert.Equal(t, feedPostID("post-1042"), posts[0].RedditID)
+    assert.Regexp(t, `^feed:[0-9a-f]{16}$`, posts[0].RedditID)
+    assert.Equal(t, "Introducing our new reasoning model", posts[0].Title)
+    // content:encoded wins over description, with markup removed
+    assert.Equal(t, "Today we are releasing a model that reasons step by step.\n\nWeights are available now.", posts[0].Body)
+    assert.Equal(t, "https://blog.example.com/posts/reasoning-model", posts[0].Permalink)
+    assert.Equal(t, "Jane Researcher", posts[0].Author)
+    assert.Equal(t, []string{"https://blog.example.com/images/chart.png"}, posts[0].MediaURLs)
+    assert.Equal(t, time.Date(2024, 3, 12, 15, 0, 0, 0, time.UTC), posts[0].CreatedAt)
+
+    // Without a GUID the link identifies the item
+    assert.Equal(t, feedPostID("https://blog.example.com/posts/roundup-11"), posts[1].RedditID)
+    assert.Equal(t, "Links from around the web", posts[1].Body)
+    assert.Equal(t, time.Date(2024, 3, 11, 9, 30, 0, 0, time.UTC), posts[1].CreatedAt)
+}
+
+func TestParseFeed_Atom(t *testing.T) {
+    posts, err := parseFeed(readFixture(t, "feed_atom.xml"))
+    require.NoError(t, err)
+    require.Len(t, posts, 2)
+
+    assert.Equal(t, feedPostID("tag:newsletter.example.com,2024:issue-37"), posts[0].RedditID)
+    assert.Equal(t, "Scaling laws, revisited", posts[0].Title)
+    assert.Equal(t, "Compute-optimal training is back.\n\nHere is why.", posts[0].Body)
+    assert.Equal(t, "https://newsletter.example.com/issues/37", posts[0].Permalink)
+    assert.Equal(t, "Alex Writer, Sam Editor", posts[0].Author)
+    assert.Equal(t, []string{"https://newsletter.example.com/audio/37.mp3"}, posts[0].MediaURLs)
+    assert.Equal(t, time.Date(2024, 3, 12, 18, 30, 2, 0, time.UTC), posts[0].CreatedAt)
+
+    // Falls back to the summary and the updated date
+    assert.Equal(t, "https://newsletter.example.com/issues/36", posts[1].Permalink)
+    assert.Equal(t, "Last week", posts[1].Body)
+    assert.Equal(t, time.Date(2024, 3, 5, 9, 0, 0, 0, time.UTC), posts[1].CreatedAt)
+}
+
+func TestParseFeed_StableIDs(t *testing.T) {
+    data := readFixture(t, "feed_rss.xml")
+
+    first, err := parseFeed(data)
+    require.NoError(t, err)
+    second, err := parseFeed(data)
+    require.NoError(t, err)
+
+    assert.Equal(t, first[0].RedditID, second[0].RedditID)
+    assert.NotEqual(t, first[0].RedditID, first[1].RedditID)
+}
+
+func TestParseFeed_Unsupported(t *testing.T) {
+    _, err := parseFeed([]byte(`<html><body>not a feed</body></html>`))
+    assert.ErrorContains(t, err, "unsupported feed format")
+
+    _, err = parseFeed([]byte(``))
+    assert.Error(t, err)
+}
+
+func TestFeedScraper_FetchResults(t *testing.T) {
+    rss := readFixture(t, "feed_rss.xml")
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if r.URL.Path != "/feed.xml" {
+            w.WriteHeader(http.StatusNotFound)
+            return
+        }
+        w.Header().Set("Content-Type", "application/rss+xml")
+        w.Write(rss)
+    }))
+    defer server.Close()
+
+    scraper := NewFeed([]config.Source{
+        {Type: config.SourceFeed, URL: server.URL + "/feed.xml"},
+        {Type: config.SourceFeed, URL: server.URL + "/missing.xml"},
+    })
+    results := scraper.FetchResults(context.Background())
+    require.Len(t, results, 2)
+
+    assert.NoError(t, results[0].Err)
+    assert.Len(t, results[0].Posts, 2)
+    assert.Error(t, results[1].Err)
+}
+
+type staticScraper struct {
+    posts []storage.Post
+}
+
+func (s staticScraper) FetchPosts(ctx context.Context) ([]storage.Post, error) {
+    return s.posts, nil
+}
+
+func TestMulti_FetchPosts(t *testing.T) {
+    rss := readFixture(t, "feed_rss.xml")
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.Write(rss)
+    }))
+    defer server.Close()
+
+    multi := NewMulti(
+        staticScraper{posts: []storage.Post{{RedditID: "abc", Title: "From Reddit"}}},
+        NewFeed([]config.Source{{Type: config.SourceFeed, URL: server.URL}}),
+    )
+
+    results := multi.FetchResults(context.Background())
+    require.Len(t, results, 2)
+    assert.Equal(t, server.URL, results[1].Source)
+
+    posts, err := multi.FetchPosts(context.Background())
+    require.NoError(t, err)
+    require.Len(t, posts, 3)
+    assert.Equal(t, "abc", posts[0].RedditID)
+    assert.Equal(t, "Introducing our new reasoning model", posts[1].Title)
+}
+
+func TestFromConfig_GroupsSourcesByType(t *testing.T) {
+    cfg := &config.Config{
+        Sources: []config.Source{
+            {Type: config.SourceReddit, URL: "https://www.reddit.com/r/MachineLearning/top/"},
+            {Type: config.SourceFeed, URL: "https://blog.example.com/feed.xml"},
+        },
+    }
+    multi, ok := FromConfig(cfg, nil).(*Multi)
+    require.True(t, ok)
+    require.Len(t, multi.scrapers, 2)
+    assert.IsType(t, &JSONScraper{}, multi.scrapers[0])
+    assert.IsType(t, &FeedScraper{}, multi.scrapers[1])
+
+    cfg.Sources = cfg.Sources[1:]
+    assert.IsType(t, &FeedScraper{}, FromConfig(cfg, nil))
+}
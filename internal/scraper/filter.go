@@ -0,0 +1,89 @@
+package scraper
+
+import (
+    "fmt"
+    "log"
+    "strings"
+
+    "github.com/w1zzzle/ai-newsbot/internal/config"
+    "github.com/w1zzzle/ai-newsbot/internal/storage"
+)
+
+// applyRules drops posts rejected by the source's include and exclude
+// rules, logging each with the reason, and reports how many were dropped
+func applyRules(source config.Source, posts []storage.Post) ([]storage.Post, int) {
+    var kept []storage.Post
+    filtered := 0
+
+    for _, post := range posts {
+        if reason := rejectReason(source, post); reason != "" {
+            log.Printf("Skipping post %s from %s: %s", post.RedditID, source.URL, reason)
+            filtered++
+            continue
+        }
+        kept = append(kept, post)
+    }
+
+    return kept, filtered
+}
+
+// rejectReason explains why the source's rules reject the post, or returns
+// an empty string when the post is kept
+func rejectReason(source config.Source, post storage.Post) string {
+    flags := postFlags(post)
+
+    if flair, ok := matchFlair(post, source.Exclude.Flairs); ok {
+        return fmt.Sprintf("flair %q is excluded", flair)
+    }
+    for _, flag := range source.Exclude.Flags {
+        if flags[flag] {
+            return fmt.Sprintf("flagged %s", flag)
+        }
+    }
+
+    if len(source.Include.Flairs) > 0 {
+        if _, ok := matchFlair(post, source.Include.Flairs); !ok {
+            if post.Flair == "" {
+                return "has no included flair"
+            }
+            return fmt.Sprintf("flair %q is not included", post.Flair)
+        }
+    }
+    if len(source.Include.Flags) > 0 {
+        included := false
+        for _, flag := range source.Include.Flags {
+            included = included || flags[flag]
+        }
+        if !included {
+            return fmt.Sprintf("not flagged %s", strings.Join(source.Include.Flags, " or "))
+        }
+    }
+
+    return ""
+}
+
+// postFlags returns the flags set on a post
+func postFlags(post storage.Post) map[string]bool {
+    return map[string]bool{
+        config.FlagNSFW:     post.NSFW,
+        config.FlagSpoiler:  post.Spoiler,
+        config.FlagStickied: post.Stickied,
+        config.FlagSelf:     post.IsSelf,
+        config.FlagLink:     !post.IsSelf,
+        config.FlagRemoved:  post.RemovedBy != "",
+    }
+}
+
+// matchFlair returns the first of flairs equal to the post's flair,
+// ignoring case
+func matchFlair(post storage.Post, flairs []string) (string, bool) {
+    if post.Flair == "" {
+        return "", false
+    }
+    for _, flair := range flairs {
+        if strings.EqualFold(strings.TrimSpace(flair), post.Flair) {
+            return flair, true
+        }
+    }
+    return "", false
+}
@@ -0,0 +1,118 @@
+package scraper
+
+import (
+    "context"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "github.com/w1zzzle/ai-newsbot/internal/config"
+    "github.com/w1zzzle/ai-newsbot/internal/storage"
+)
+
+const flaggedListing = `{"kind": "Listing", "data": {"children": [
+    {"kind": "t3", "data": {"id": "st1", "title": "Weekly discussion thread", "score": 900, "is_self": true, "stickied": true, "link_flair_text": "Discussion", "permalink": "/r/ml/comments/st1/"}},
+    {"kind": "t3", "data": {"id": "ns2", "title": "Model outputs", "score": 800, "over_18": true, "link_flair_text": "Research", "permalink": "/r/ml/comments/ns2/"}},
+    {"kind": "t3", "data": {"id": "rm3", "title": "Leaked weights", "score": 700, "removed_by_category": "moderator", "permalink": "/r/ml/comments/rm3/"}},
+    {"kind": "t3", "data": {"id": "ok4", "title": "New benchmark results", "score": 600, "is_self": true, "spoiler": true, "link_flair_text": " Research ", "permalink": "/r/ml/comments/ok4/"}}
+]}}`
+
+func TestJSONScraper_FetchResults_AppliesRules(t *testing.T) {
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.Header().Set("Content-Type", "application/json")
+        w.Write([]byte(flaggedListing))
+    }))
+    defer server.Close()
+
+    source := config.Source{
+        URL:       server.URL + "/r/ml/top/",
+        PageDepth: 1,
+        Exclude:   config.PostRules{Flags: config.DefaultExcludeFlags},
+    }
+    results := NewJSONFromSources([]config.Source{source}).FetchResults(context.Background())
+    require.Len(t, results, 1)
+    require.NoError(t, results[0].Err)
+
+    assert.Equal(t, 3, results[0].Filtered)
+    require.Len(t, results[0].Posts, 1)
+
+    post := results[0].Posts[0]
+    assert.Equal(t, "ok4", post.RedditID)
+    assert.Equal(t, "Research", post.Flair)
+    assert.True(t, post.Spoiler)
+    assert.True(t, post.IsSelf)
+    assert.False(t, post.NSFW)
+}
+
+func TestRedditScraper_FetchResults_ReadsFlags(t *testing.T) {
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.WriteHeader(http.StatusOK)
+        w.Write([]byte(`<html><body>
+            <shreddit-post nsfw="" post-title="Model outputs">
+                <div data-testid="post-container" data-post-id="ns1">
+                    <h3 data-testid="post-title">Model outputs</h3>
+                </div>
+            </shreddit-post>
+            <shreddit-post spoiler stickied>
+                <div data-testid="post-container" data-post-id="st2">
+                    <h3 data-testid="post-title">Weekly discussion thread</h3>
+                </div>
+            </shreddit-post>
+            <div data-testid="post-container" data-post-id="ok3">
+                <h3 data-testid="post-title">New benchmark results</h3>
+                <shreddit-post nsfw="false"></shreddit-post>
+            </div>
+        </body></html>`))
+    }))
+    defer server.Close()
+
+    source := config.Source{URL: server.URL}
+    results := NewFromSources([]config.Source{source}).FetchResults(context.Background())
+    require.Len(t, results, 1)
+    require.NoError(t, results[0].Err)
+    require.Len(t, results[0].Posts, 3)
+
+    nsfw, stickied, plain := results[0].Posts[0], results[0].Posts[1], results[0].Posts[2]
+    assert.True(t, nsfw.NSFW)
+    assert.False(t, nsfw.Spoiler)
+    assert.True(t, stickied.Spoiler)
+    assert.True(t, stickied.Stickied)
+    assert.False(t, plain.NSFW)
+
+    // The default rules drop the NSFW and stickied posts
+    source.Exclude = config.PostRules{Flags: config.DefaultExcludeFlags}
+    results = NewFromSources([]config.Source{source}).FetchResults(context.Background())
+    require.Len(t, results, 1)
+    assert.Equal(t, 2, results[0].Filtered)
+    assert.Equal(t, []string{"ok3"}, postIDs(results[0].Posts))
+}
+
+func TestRejectReason(t *testing.T) {
+    discussion := storage.Post{RedditID: "a", Flair: "Discussion", IsSelf: true}
+    research := storage.Post{RedditID: "b", Flair: "Research"}
+    unflaired := storage.Post{RedditID: "c", IsSelf: true}
+
+    tests := []struct {
+        name     string
+        rules    config.Source
+        post     storage.Post
+        expected string
+    }{
+        {"no rules", config.Source{}, discussion, ""},
+        {"excluded flair", config.Source{Exclude: config.PostRules{Flairs: []string{"discussion"}}}, discussion, `flair "discussion" is excluded`},
+        {"excluded flag", config.Source{Exclude: config.PostRules{Flags: []string{config.FlagSelf}}}, discussion, "flagged self"},
+        {"included flair", config.Source{Include: config.PostRules{Flairs: []string{"Research", "News"}}}, research, ""},
+        {"flair not included", config.Source{Include: config.PostRules{Flairs: []string{"Research"}}}, discussion, `flair "Discussion" is not included`},
+        {"no flair", config.Source{Include: config.PostRules{Flairs: []string{"Research"}}}, unflaired, "has no included flair"},
+        {"included flag", config.Source{Include: config.PostRules{Flags: []string{config.FlagLink}}}, research, ""},
+        {"flag not included", config.Source{Include: config.PostRules{Flags: []string{config.FlagLink, config.FlagSpoiler}}}, unflaired, "not flagged link or spoiler"},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            assert.Equal(t, tt.expected, rejectReason(tt.rules, tt.post))
+        })
+    }
+}
@@ -0,0 +1,234 @@
+package scraper
+
+import (
+    "bytes"
+    "crypto/sha1"
+    "encoding/base64"
+    "encoding/hex"
+    "encoding/json"
+    "fmt"
+    "io"
+    "net/http"
+    "os"
+    "path/filepath"
+    "regexp"
+    "strings"
+    "sync"
+    "time"
+    "unicode/utf8"
+)
+
+// Fixture modes
+const (
+    // FixtureRecord fetches from the network and saves every exchange
+    FixtureRecord = "record"
+    // FixtureReplay serves saved exchanges and never touches the network
+    FixtureReplay = "replay"
+)
+
+// WithFixtures records HTTP exchanges into dir, or replays them from it,
+// depending on mode. Recording production traffic and replaying it
+// offline reproduces a bad parse without hitting the source again.
+func WithFixtures(mode, dir string) Option {
+    return func(f *fetcher) {
+        f.client.Transport = newFixtureTransport(mode, dir, f.client.Transport)
+    }
+}
+
+// Fixture is one recorded exchange. Request headers and bodies are not
+// kept, cookies are dropped and token fields of JSON bodies are redacted,
+// so credentials never end up in a fixture.
+type Fixture struct {
+    Method     string      `json:"method"`
+    URL        string      `json:"url"`
+    RecordedAt time.Time   `json:"recorded_at"`
+    Status     int         `json:"status"`
+    Header     http.Header `json:"header"`
+    // Body holds text bodies as is and BodyBase64 anything else
+    Body       string `json:"body,omitempty"`
+    BodyBase64 string `json:"body_base64,omitempty"`
+}
+
+// fixtureTransport records or replays exchanges as one JSON file each
+type fixtureTransport struct {
+    mode string
+    dir  string
+    next http.RoundTripper
+
+    mu sync.Mutex
+}
+
+func newFixtureTransport(mode, dir string, next http.RoundTripper) *fixtureTransport {
+    if next == nil {
+        next = http.DefaultTransport
+    }
+    return &fixtureTransport{mode: mode, dir: dir, next: next}
+}
+
+func (t *fixtureTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+    switch t.mode {
+    case FixtureReplay:
+        return t.replay(req)
+    case FixtureRecord:
+        return t.record(req)
+    default:
+        return nil, fmt.Errorf("unknown fixture mode %q", t.mode)
+    }
+}
+
+func (t *fixtureTransport) replay(req *http.Request) (*http.Response, error) {
+    data, err := os.ReadFile(filepath.Join(t.dir, fixtureName(req.Method, req.URL.String())))
+    if os.IsNotExist(err) {
+        return nil, fmt.Errorf("no fixture for %s %s in %s", req.Method, req.URL, t.dir)
+    }
+    if err != nil {
+        return nil, err
+    }
+
+    var fixture Fixture
+    if err := json.Unmarshal(data, &fixture); err != nil {
+        return nil, fmt.Errorf("failed to decode fixture for %s: %w", req.URL, err)
+    }
+
+    body := []byte(fixture.Body)
+    if fixture.BodyBase64 != "" {
+        if body, err = base64.StdEncoding.DecodeString(fixture.BodyBase64); err != nil {
+            return nil, fmt.Errorf("failed to decode fixture body for %s: %w", req.URL, err)
+        }
+    }
+
+    return &http.Response{
+        Status:        fmt.Sprintf("%d %s", fixture.Status, http.StatusText(fixture.Status)),
+        StatusCode:    fixture.Status,
+        Proto:         "HTTP/1.1",
+        ProtoMajor:    1,
+        ProtoMinor:    1,
+        Header:        fixture.Header,
+        Body:          io.NopCloser(bytes.NewReader(body)),
+        ContentLength: int64(len(body)),
+        Request:       req,
+    }, nil
+}
+
+func (t *fixtureTransport) record(req *http.Request) (*http.Response, error) {
+    resp, err := t.next.RoundTrip(req)
+    if err != nil {
+        return nil, err
+    }
+
+    body, err := io.ReadAll(resp.Body)
+    resp.Body.Close()
+    if err != nil {
+        return nil, err
+    }
+    resp.Body = io.NopCloser(bytes.NewReader(body))
+
+    // 304s depend on validators the replaying side doesn't have
+    if resp.StatusCode == http.StatusNotModified {
+        return resp, nil
+    }
+
+    header := resp.Header.Clone()
+    header.Del("Set-Cookie")
+
+    fixture := Fixture{
+        Method:     req.Method,
+        URL:        req.URL.String(),
+        RecordedAt: time.Now().UTC(),
+        Status:     resp.StatusCode,
+        Header:     header,
+    }
+    if utf8.Valid(body) {
+        fixture.Body = string(redactTokens(body))
+    } else {
+        fixture.BodyBase64 = base64.StdEncoding.EncodeToString(body)
+    }
+
+    if err := t.save(fixture); err != nil {
+        return nil, fmt.Errorf("failed to record fixture for %s: %w", req.URL, err)
+    }
+    return resp, nil
+}
+
+func (t *fixtureTransport) save(fixture Fixture) error {
+    // Unescaped HTML keeps recorded pages readable in diffs
+    var data bytes.Buffer
+    encoder := json.NewEncoder(&data)
+    encoder.SetEscapeHTML(false)
+    encoder.SetIndent("", "  ")
+    if err := encoder.Encode(fixture); err != nil {
+        return err
+    }
+
+    t.mu.Lock()
+    defer t.mu.Unlock()
+
+    if err := os.MkdirAll(t.dir, 0o755); err != nil {
+        return err
+    }
+    return os.WriteFile(filepath.Join(t.dir, fixtureName(fixture.Method, fixture.URL)), data.Bytes(), 0o644)
+}
+
+// secretFields are the JSON fields of OAuth token responses
+var secretFields = map[string]bool{
+    "access_token":  true,
+    "refresh_token": true,
+    "id_token":      true,
+}
+
+// redactTokens replaces the values of secretFields anywhere in a JSON body.
+// Other bodies, and JSON without such fields, are returned unchanged.
+func redactTokens(body []byte) []byte {
+    var v any
+    if err := json.Unmarshal(body, &v); err != nil || !redact(v) {
+        return body
+    }
+
+    redacted, err := json.Marshal(v)
+    if err != nil {
+        return body
+    }
+    return redacted
+}
+
+// redact replaces secret values in a decoded JSON value and reports
+// whether it found any
+func redact(v any) bool {
+    found := false
+    switch v := v.(type) {
+    case map[string]any:
+        for key, value := range v {
+            if secretFields[key] {
+                v[key] = "REDACTED"
+                found = true
+            } else if redact(value) {
+                found = true
+            }
+        }
+    case []any:
+        for _, value := range v {
+            if redact(value) {
+                found = true
+            }
+        }
+    }
+    return found
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// fixtureName derives a readable, unique file name from a request
+func fixtureName(method, rawURL string) string {
+    sum := sha1.Sum([]byte(method + " " + rawURL))
+
+    slug := rawURL
+    if i := strings.Index(slug, "://"); i >= 0 {
+        slug = slug[i+3:]
+    }
+    slug = strings.Trim(unsafeFileChars.ReplaceAllString(slug, "_"), "_")
+    if len(slug) > 80 {
+        slug = slug[:80]
+    }
+
+    return fmt.Sprintf("%s_%s_%s.json", strings.ToLower(method), slug, hex.EncodeToString(sum[:])[:8])
+}
@@ -0,0 +1,127 @@
+package scraper
+
+import (
+    "context"
+    "io"
+    "net/http"
+    "net/http/httptest"
+    "net/url"
+    "os"
+    "strings"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+)
+
+func TestFixtureTransport_RecordThenReplay(t *testing.T) {
+    dir := t.TempDir()
+    requests := 0
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        requests++
+        http.SetCookie(w, &http.Cookie{Name: "session", Value: "secret"})
+        w.Header().Set("Content-Type", "text/html")
+        w.Write([]byte(`<html><body>
+            <div data-testid="post-container" data-post-id="rec1" score="420" created-timestamp="2024-03-11T13:00:00+00:00">
+                <h3 data-testid="post-title">Recorded post</h3>
+            </div>
+        </body></html>`))
+    }))
+    defer server.Close()
+
+    recorded, err := New([]string{server.URL + "/r/test/"}, 0, WithFixtures(FixtureRecord, dir)).FetchPosts(context.Background())
+    require.NoError(t, err)
+    require.Len(t, recorded, 1)
+    assert.Equal(t, 1, requests)
+
+    files, err := os.ReadDir(dir)
+    require.NoError(t, err)
+    require.Len(t, files, 1)
+    data, err := os.ReadFile(dir + "/" + files[0].Name())
+    require.NoError(t, err)
+    assert.Contains(t, string(data), "Recorded post</h3>")
+    assert.NotContains(t, string(data), "secret")
+
+    // Replay serves the same posts without contacting the server
+    server.Close()
+    replayed, err := New([]string{server.URL + "/r/test/"}, 0, WithFixtures(FixtureReplay, dir)).FetchPosts(context.Background())
+    require.NoError(t, err)
+    assert.Equal(t, recorded, replayed)
+    assert.Equal(t, 1, requests)
+}
+
+func TestFixtureTransport_RedactsTokens(t *testing.T) {
+    dir := t.TempDir()
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.Header().Set("Content-Type", "application/json")
+        w.Write([]byte(`{"access_token": "secret-access", "refresh_token": "secret-refresh", "token_type": "bearer", "expires_in": 86400}`))
+    }))
+    defer server.Close()
+
+    recorder := &http.Client{Transport: newFixtureTransport(FixtureRecord, dir, nil)}
+    resp, err := recorder.PostForm(server.URL+"/api/v1/access_token", url.Values{"password": {"hunter2"}})
+    require.NoError(t, err)
+    body, err := io.ReadAll(resp.Body)
+    resp.Body.Close()
+    require.NoError(t, err)
+
+    // The caller still gets the real response
+    assert.Contains(t, string(body), "secret-access")
+
+    files, err := os.ReadDir(dir)
+    require.NoError(t, err)
+    require.Len(t, files, 1)
+    data, err := os.ReadFile(dir + "/" + files[0].Name())
+    require.NoError(t, err)
+    assert.NotContains(t, string(data), "secret-")
+    assert.NotContains(t, string(data), "hunter2")
+    assert.Contains(t, string(data), `\"token_type\":\"bearer\"`)
+}
+
+func TestRedactTokens(t *testing.T) {
+    assert.JSONEq(t,
+        `{"data": [{"access_token": "REDACTED"}], "kind": "Listing"}`,
+        string(redactTokens([]byte(`{"data": [{"access_token": "abc"}], "kind": "Listing"}`))))
+
+    // Bodies without tokens are kept byte for byte
+    listing := []byte(`{"kind":  "Listing"}`)
+    assert.Equal(t, listing, redactTokens(listing))
+    page := []byte(`<html>access_token</html>`)
+    assert.Equal(t, page, redactTokens(page))
+}
+
+func TestFixtureTransport_ReplayMissing(t *testing.T) {
+    client := &http.Client{Transport: newFixtureTransport(FixtureReplay, t.TempDir(), nil)}
+
+    _, err := client.Get("https://www.reddit.com/r/unrecorded/")
+    assert.ErrorContains(t, err, "no fixture for GET https://www.reddit.com/r/unrecorded/")
+}
+
+func TestFixtureTransport_BinaryBody(t *testing.T) {
+    dir := t.TempDir()
+    image := []byte{0x89, 'P', 'N', 'G', 0xff, 0x00, 0xfe}
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.Write(image)
+    }))
+    defer server.Close()
+
+    recorder := &http.Client{Transport: newFixtureTransport(FixtureRecord, dir, nil)}
+    resp, err := recorder.Get(server.URL + "/chart.png")
+    require.NoError(t, err)
+    resp.Body.Close()
+
+    replayer := &http.Client{Transport: newFixtureTransport(FixtureReplay, dir, nil)}
+    resp, err = replayer.Get(server.URL + "/chart.png")
+    require.NoError(t, err)
+    defer resp.Body.Close()
+
+    body, err := io.ReadAll(resp.Body)
+    require.NoError(t, err)
+    assert.Equal(t, image, body)
+}
+
+func TestFixtureName(t *testing.T) {
+    name := fixtureName("GET", "https://www.reddit.com/r/MachineLearning/top.json?t=day")
+    assert.True(t, strings.HasPrefix(name, "get_www.reddit.com_r_MachineLearning_top.json_t_day_"))
+    assert.NotEqual(t, name, fixtureName("GET", "https://www.reddit.com/r/MachineLearning/top.json?t=week"))
+}
@@ -0,0 +1,172 @@
+package scraper
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "net/url"
+    "strings"
+    "time"
+
+    "github.com/w1zzzle/ai-newsbot/internal/config"
+    "github.com/w1zzzle/ai-newsbot/internal/storage"
+)
+
+const (
+    githubAPIURL = "https://api.github.com"
+    // githubReleasesPerPage is how many recent releases are read per repo
+    githubReleasesPerPage = 10
+)
+
+// GitHubConfig selects the GitHub API to talk to. Token is optional but
+// raises the rate limit from 60 to 5000 requests an hour.
+type GitHubConfig struct {
+    APIBaseURL string
+    Token      string
+}
+
+// GitHubScraper turns new releases of tracked repositories into posts. A
+// source's URL is the repository, either as owner/repo or as its
+// https://github.com/owner/repo page.
+type GitHubScraper struct {
+    sources []config.Source
+    http    *fetcher
+    cfg     GitHubConfig
+}
+
+// githubRelease mirrors the parts of a GitHub release we use
+type githubRelease struct {
+    ID          int64     `json:"id"`
+    TagName     string    `json:"tag_name"`
+    Name        string    `json:"name"`
+    Body        string    `json:"body"`
+    HTMLURL     string    `json:"html_url"`
+    Draft       bool      `json:"draft"`
+    Prerelease  bool      `json:"prerelease"`
+    PublishedAt time.Time `json:"published_at"`
+    Author      struct {
+        Login string `json:"login"`
+    } `json:"author"`
+}
+
+// NewGitHub creates a scraper for GitHub releases
+func NewGitHub(sources []config.Source, cfg GitHubConfig, opts ...Option) *GitHubScraper {
+    if cfg.APIBaseURL == "" {
+        cfg.APIBaseURL = githubAPIURL
+    }
+    cfg.APIBaseURL = strings.TrimSuffix(cfg.APIBaseURL, "/")
+
+    return &GitHubScraper{
+        sources: sources,
+        http:    newFetcher(opts...),
+        cfg:     cfg,
+    }
+}
+
+func (s *GitHubScraper) FetchPosts(ctx context.Context) ([]storage.Post, error) {
+    return collectPosts(s.FetchResults(ctx))
+}
+
+func (s *GitHubScraper) FetchResults(ctx context.Context) []SourceResult {
+    return s.http.fetchConcurrently(ctx, s.sources, func(ctx context.Context, source config.Source) SourceResult {
+        posts, err := s.fetchReleases(ctx, source)
+        return sourceResult(source, posts, err)
+    })
+}
+
+func (s *GitHubScraper) fetchReleases(ctx context.Context, source config.Source) ([]storage.Post, error) {
+    repo, err := githubRepo(source.URL)
+    if err != nil {
+        return nil, err
+    }
+
+    header := http.Header{
+        "Accept":               {"application/vnd.github+json"},
+        "X-Github-Api-Version": {"2022-11-28"},
+    }
+    if s.cfg.Token != "" {
+        header.Set("Authorization", "Bearer "+s.cfg.Token)
+    }
+
+    // Conditional requests answered with 304 don't count against the
+    // GitHub rate limit
+    releasesURL := fmt.Sprintf("%s/repos/%s/releases?per_page=%d", s.cfg.APIBaseURL, repo, githubReleasesPerPage)
+    resp, err := s.http.get(ctx, releasesURL, header)
+    if err != nil {
+        return nil, err
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode != http.StatusOK {
+        return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
+    }
+
+    var releases []githubRelease
+    if err := json.NewDecoder(resp.Body).Decode(&releases); err != nil {
+        return nil, fmt.Errorf("failed to decode releases of %s: %w", repo, err)
+    }
+
+    var posts []storage.Post
+    for _, release := range releases {
+        if release.Draft || (release.Prerelease && source.SkipPrereleases) {
+            continue
+        }
+        post := release.toPost(repo)
+        if !matchesKeywords(post, source.Keywords) {
+            continue
+        }
+        posts = append(posts, post)
+    }
+
+    s.http.remember(ctx, resp)
+    return posts, nil
+}
+
+// githubRepo returns owner/repo from either owner/repo or a repository URL
+func githubRepo(source string) (string, error) {
+    repo := strings.TrimSpace(source)
+    if strings.Contains(repo, "://") {
+        u, err := url.Parse(repo)
+        if err != nil {
+            return "", err
+        }
+        repo = u.Path
+    }
+    repo = strings.TrimSuffix(strings.Trim(repo, "/"), ".git")
+
+    parts := strings.Split(repo, "/")
+    if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+        return "", fmt.Errorf("not a GitHub repository: %s", source)
+    }
+    return repo, nil
+}
+
+func (release githubRelease) toPost(repo string) storage.Post {
+    name := strings.TrimSpace(release.Name)
+    if name == "" {
+        name = release.TagName
+    }
+
+    // Release names often repeat the project, e.g. "vLLM v0.4.0"
+    title := name
+    project := repo[strings.Index(repo, "/")+1:]
+    if !strings.Contains(strings.ToLower(name), strings.ToLower(project)) {
+        title = project + " " + name
+    }
+
+    return storage.Post{
+        RedditID:  githubPostID(repo, release.TagName),
+        Title:     title,
+        Body:      strings.TrimSpace(release.Body),
+        Permalink: release.HTMLURL,
+        Author:    release.Author.Login,
+        CreatedAt: release.PublishedAt,
+    }
+}
+
+// githubPostID keys a release by repository and tag, which stay stable
+// when the release notes are edited
+func githubPostID(repo, tag string) string {
+    return "github:" + strings.ToLower(repo) + "@" + tag
+}
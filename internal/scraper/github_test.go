@@ -0,0 +1,80 @@
+package scraper
+
+import (
+    "context"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "github.com/w1zzzle/ai-newsbot/internal/config"
+)
+
+func TestGitHubScraper_FetchPosts(t *testing.T) {
+    var requested *http.Request
+    fixture := serveFixture(t, "github_releases.json")
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        requested = r
+        fixture(w, r)
+    }))
+    defer server.Close()
+
+    scraper := NewGitHub([]config.Source{
+        {Type: config.SourceGitHub, URL: "https://github.com/vllm-project/vllm"},
+    }, GitHubConfig{APIBaseURL: server.URL + "/", Token: "ghp_test"})
+    posts, err := scraper.FetchPosts(context.Background())
+    require.NoError(t, err)
+
+    assert.Equal(t, "/repos/vllm-project/vllm/releases", requested.URL.Path)
+    assert.Equal(t, "Bearer ghp_test", requested.Header.Get("Authorization"))
+    assert.Equal(t, "application/vnd.github+json", requested.Header.Get("Accept"))
+
+    // Drafts are never posted
+    require.Len(t, posts, 3)
+    assert.Equal(t, "github:vllm-project/vllm@v0.4.1rc1", posts[0].RedditID)
+    assert.Equal(t, "vllm v0.4.1rc1", posts[0].Title)
+
+    assert.Equal(t, "github:vllm-project/vllm@v0.4.0", posts[1].RedditID)
+    assert.Equal(t, "vLLM v0.4.0", posts[1].Title)
+    assert.Contains(t, posts[1].Body, "* Support for Llama 3")
+    assert.Equal(t, "https://github.com/vllm-project/vllm/releases/tag/v0.4.0", posts[1].Permalink)
+    assert.Equal(t, "WoosukKwon", posts[1].Author)
+    assert.Equal(t, time.Date(2024, 4, 2, 19, 36, 5, 0, time.UTC), posts[1].CreatedAt)
+
+    // Unnamed releases fall back to the tag
+    assert.Equal(t, "vllm v0.3.3", posts[2].Title)
+}
+
+func TestGitHubScraper_SkipPrereleases(t *testing.T) {
+    server := httptest.NewServer(serveFixture(t, "github_releases.json"))
+    defer server.Close()
+
+    scraper := NewGitHub([]config.Source{
+        {Type: config.SourceGitHub, URL: "vllm-project/vllm", SkipPrereleases: true},
+    }, GitHubConfig{APIBaseURL: server.URL})
+    posts, err := scraper.FetchPosts(context.Background())
+    require.NoError(t, err)
+
+    assert.Equal(t, []string{"github:vllm-project/vllm@v0.4.0", "github:vllm-project/vllm@v0.3.3"}, postIDs(posts))
+}
+
+func TestGitHubRepo(t *testing.T) {
+    tests := map[string]string{
+        "ggerganov/llama.cpp":                         "ggerganov/llama.cpp",
+        "https://github.com/huggingface/transformers": "huggingface/transformers",
+        "https://github.com/vllm-project/vllm.git":    "vllm-project/vllm",
+        " https://github.com/vllm-project/vllm/ ":     "vllm-project/vllm",
+    }
+    for input, want := range tests {
+        repo, err := githubRepo(input)
+        require.NoError(t, err, input)
+        assert.Equal(t, want, repo)
+    }
+
+    for _, input := range []string{"llama.cpp", "https://github.com/vllm-project/vllm/releases", ""} {
+        _, err := githubRepo(input)
+        assert.Error(t, err, input)
+    }
+}
@@ -0,0 +1,219 @@
+package scraper
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "log"
+    "net/http"
+    "net/url"
+    "path"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/w1zzzle/ai-newsbot/internal/config"
+    "github.com/w1zzzle/ai-newsbot/internal/storage"
+)
+
+const (
+    hnBaseURL = "https://hacker-news.firebaseio.com/v0"
+    hnItemURL = "https://news.ycombinator.com/item?id="
+    // hnPageSize matches the number of stories on a page of the HN site
+    hnPageSize = 30
+)
+
+// HackerNewsScraper reads story lists such as topstories.json from the
+// Hacker News Firebase API. A source's URL is the list endpoint, and items
+// are fetched from the same base URL.
+type HackerNewsScraper struct {
+    sources []config.Source
+    http    *fetcher
+    seen    SeenChecker
+}
+
+// hnItem mirrors the parts of a Hacker News item we use
+type hnItem struct {
+    ID          int    `json:"id"`
+    Type        string `json:"type"`
+    By          string `json:"by"`
+    Time        int64  `json:"time"`
+    Title       string `json:"title"`
+    URL         string `json:"url"`
+    Text        string `json:"text"`
+    Score       int    `json:"score"`
+    Descendants int    `json:"descendants"`
+    Dead        bool   `json:"dead"`
+    Deleted     bool   `json:"deleted"`
+}
+
+// NewHackerNews creates a scraper for Hacker News story lists
+func NewHackerNews(sources []config.Source, opts ...Option) *HackerNewsScraper {
+    return &HackerNewsScraper{
+        sources: sources,
+        http:    newFetcher(opts...),
+    }
+}
+
+// WithSeenChecker skips fetching stories that are already stored
+func (s *HackerNewsScraper) WithSeenChecker(seen SeenChecker) *HackerNewsScraper {
+    s.seen = seen
+    return s
+}
+
+func (s *HackerNewsScraper) FetchPosts(ctx context.Context) ([]storage.Post, error) {
+    return collectPosts(s.FetchResults(ctx))
+}
+
+func (s *HackerNewsScraper) FetchResults(ctx context.Context) []SourceResult {
+    return s.http.fetchConcurrently(ctx, s.sources, func(ctx context.Context, source config.Source) SourceResult {
+        posts, err := s.fetchStories(ctx, source)
+        return sourceResult(source, posts, err)
+    })
+}
+
+// fetchStories reads a story list and fetches its first PageDepth pages of
+// stories, keeping those that match the source's keywords
+func (s *HackerNewsScraper) fetchStories(ctx context.Context, source config.Source) ([]storage.Post, error) {
+    var ids []int
+    if err := s.getJSON(ctx, source.URL, &ids); err != nil {
+        return nil, err
+    }
+
+    limit := max(source.PageDepth, 1) * hnPageSize
+    if len(ids) > limit {
+        ids = ids[:limit]
+    }
+
+    base, err := hnItemBase(source.URL)
+    if err != nil {
+        return nil, err
+    }
+
+    // Items are fetched one request each, so they share the worker pool
+    // rather than waiting on each other
+    stories := make([]*storage.Post, len(ids))
+    s.http.forEach(len(ids), func(i int) {
+        stories[i] = s.fetchStory(ctx, base, ids[i], source.Keywords)
+    })
+    if ctx.Err() != nil {
+        return nil, ctx.Err()
+    }
+
+    var posts []storage.Post
+    for _, post := range stories {
+        if post != nil {
+            posts = append(posts, *post)
+        }
+    }
+
+    return posts, nil
+}
+
+// fetchStory returns the story with the given ID, or nil if it is already
+// stored, failed to load or doesn't match keywords
+func (s *HackerNewsScraper) fetchStory(ctx context.Context, base string, id int, keywords []string) *storage.Post {
+    if ctx.Err() != nil || s.isSeen(ctx, hnPostID(id)) {
+        return nil
+    }
+
+    var item hnItem
+    if err := s.getJSON(ctx, fmt.Sprintf("%s/item/%d.json", base, id), &item); err != nil {
+        if ctx.Err() == nil {
+            log.Printf("Error fetching Hacker News item %d: %v", id, err)
+        }
+        return nil
+    }
+
+    post := item.toPost()
+    if post == nil || !matchesKeywords(*post, keywords) {
+        return nil
+    }
+    return post
+}
+
+func (s *HackerNewsScraper) isSeen(ctx context.Context, id string) bool {
+    if s.seen == nil {
+        return false
+    }
+    seen, err := s.seen.IsPostSeen(ctx, id)
+    if err != nil {
+        log.Printf("Error checking if post %s is seen: %v", id, err)
+        return false
+    }
+    return seen
+}
+
+func (s *HackerNewsScraper) getJSON(ctx context.Context, url string, v any) error {
+    resp, err := s.http.get(ctx, url, http.Header{"Accept": {"application/json"}})
+    if err != nil {
+        return err
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode != http.StatusOK {
+        return fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
+    }
+
+    if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+        return fmt.Errorf("failed to decode %s: %w", url, err)
+    }
+    return nil
+}
+
+// hnItemBase returns the API base URL of a list endpoint, so that
+// https://hacker-news.firebaseio.com/v0/topstories.json gives
+// https://hacker-news.firebaseio.com/v0
+func hnItemBase(listURL string) (string, error) {
+    u, err := url.Parse(listURL)
+    if err != nil {
+        return "", err
+    }
+    if !strings.HasSuffix(u.Path, ".json") {
+        return "", fmt.Errorf("not a Hacker News list endpoint: %s", listURL)
+    }
+    u.Path = strings.TrimSuffix(path.Dir(u.Path), "/")
+    u.RawQuery = ""
+    return u.String(), nil
+}
+
+// hnPostID namespaces story IDs so they can't collide with Reddit IDs
+func hnPostID(id int) string {
+    return "hn:" + strconv.Itoa(id)
+}
+
+// toPost maps a story to a post. Jobs, polls, and dead or deleted stories
+// are skipped.
+func (item hnItem) toPost() *storage.Post {
+    if item.Type != "story" || item.Dead || item.Deleted || item.Title == "" {
+        return nil
+    }
+
+    return &storage.Post{
+        RedditID:    hnPostID(item.ID),
+        Title:       item.Title,
+        Body:        stripHTML(item.Text),
+        Score:       item.Score,
+        NumComments: item.Descendants,
+        Permalink:   hnItemURL + strconv.Itoa(item.ID),
+        URL:         item.URL,
+        Author:      item.By,
+        CreatedAt:   time.Unix(item.Time, 0).UTC(),
+    }
+}
+
+// matchesKeywords reports whether the post's title or body contains any of
+// the keywords, ignoring case. An empty list matches every post.
+func matchesKeywords(post storage.Post, keywords []string) bool {
+    if len(keywords) == 0 {
+        return true
+    }
+
+    text := strings.ToLower(post.Title + "\n" + post.Body)
+    for _, keyword := range keywords {
+        if keyword = strings.TrimSpace(keyword); keyword != "" && strings.Contains(text, strings.ToLower(keyword)) {
+            return true
+        }
+    }
+    return false
+}
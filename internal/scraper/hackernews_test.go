@@ -0,0 +1,166 @@
+package scraper
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "sync"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "github.com/w1zzzle/ai-newsbot/internal/config"
+)
+
+// fakeHackerNews serves a top story list and its items like the Firebase
+// API. The returned func lists the paths requested so far.
+func fakeHackerNews(t *testing.T, ids []int, items map[int]hnItem) (*httptest.Server, func() []string) {
+    t.Helper()
+    var mu sync.Mutex
+    var requested []string
+
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        mu.Lock()
+        requested = append(requested, r.URL.Path)
+        mu.Unlock()
+        w.Header().Set("Content-Type", "application/json")
+
+        if r.URL.Path == "/v0/topstories.json" {
+            json.NewEncoder(w).Encode(ids)
+            return
+        }
+
+        var id int
+        if _, err := fmt.Sscanf(r.URL.Path, "/v0/item/%d.json", &id); err != nil {
+            w.WriteHeader(http.StatusNotFound)
+            return
+        }
+        item, ok := items[id]
+        if !ok {
+            w.Write([]byte("null"))
+            return
+        }
+        json.NewEncoder(w).Encode(item)
+    }))
+
+    return server, func() []string {
+        mu.Lock()
+        defer mu.Unlock()
+        return append([]string(nil), requested...)
+    }
+}
+
+func hnStories() ([]int, map[int]hnItem) {
+    items := map[int]hnItem{
+        101: {ID: 101, Type: "story", By: "pg", Time: 1710162000, Title: "Show HN: A tiny LLM inference engine in C", URL: "https://github.com/example/tiny", Score: 412, Descendants: 98},
+        102: {ID: 102, Type: "story", By: "dang", Time: 1710165600, Title: "Ask HN: What are you using for RAG?", Text: "<p>Curious what people use.<p>Vector DBs &amp; all.", Score: 150, Descendants: 240},
+        103: {ID: 103, Type: "story", By: "someone", Time: 1710169200, Title: "The history of the typewriter", URL: "https://example.com/typewriter", Score: 300},
+        104: {ID: 104, Type: "job", By: "acme", Time: 1710169200, Title: "Acme (YC W24) is hiring LLM engineers"},
+        105: {ID: 105, Type: "story", Title: "New LLM benchmark", Score: 80, Dead: true},
+        106: {ID: 106, Type: "story", By: "newbie", Time: 1710172800, Title: "My LLM side project", Score: 3},
+    }
+    return []int{101, 102, 103, 104, 105, 106}, items
+}
+
+func TestHackerNewsScraper_FetchPosts(t *testing.T) {
+    ids, items := hnStories()
+    server, _ := fakeHackerNews(t, ids, items)
+    defer server.Close()
+
+    scraper := NewHackerNews([]config.Source{
+        {Type: config.SourceHackerNews, URL: server.URL + "/v0/topstories.json", UpvoteThreshold: 100},
+    })
+    posts, err := scraper.FetchPosts(context.Background())
+    require.NoError(t, err)
+    require.Len(t, posts, 3)
+
+    assert.Equal(t, "hn:101", posts[0].RedditID)
+    assert.Equal(t, "Show HN: A tiny LLM inference engine in C", posts[0].Title)
+    assert.Equal(t, "https://github.com/example/tiny", posts[0].URL)
+    assert.Equal(t, "https://news.ycombinator.com/item?id=101", posts[0].Permalink)
+    assert.Equal(t, 412, posts[0].Score)
+    assert.Equal(t, 98, posts[0].NumComments)
+    assert.Equal(t, "pg", posts[0].Author)
+    assert.Equal(t, time.Unix(1710162000, 0).UTC(), posts[0].CreatedAt)
+
+    // Text posts keep their body as plain text
+    assert.Equal(t, "hn:102", posts[1].RedditID)
+    assert.Equal(t, "Curious what people use.\n\nVector DBs & all.", posts[1].Body)
+
+    assert.Equal(t, "hn:103", posts[2].RedditID)
+}
+
+func TestHackerNewsScraper_Keywords(t *testing.T) {
+    ids, items := hnStories()
+    server, _ := fakeHackerNews(t, ids, items)
+    defer server.Close()
+
+    scraper := NewHackerNews([]config.Source{
+        {Type: config.SourceHackerNews, URL: server.URL + "/v0/topstories.json", Keywords: []string{"llm", "RAG"}},
+    })
+    results := scraper.FetchResults(context.Background())
+    require.Len(t, results, 1)
+    require.NoError(t, results[0].Err)
+
+    // The job and the dead story mention LLMs but are never posts
+    assert.Equal(t, []string{"hn:101", "hn:102", "hn:106"}, postIDs(results[0].Posts))
+}
+
+func TestHackerNewsScraper_SkipsSeenAndLimitsToPageDepth(t *testing.T) {
+    ids := make([]int, 0, 2*hnPageSize)
+    items := make(map[int]hnItem)
+    for id := 1; id <= 2*hnPageSize; id++ {
+        ids = append(ids, id)
+        items[id] = hnItem{ID: id, Type: "story", Title: fmt.Sprintf("Story %d", id), Score: 10}
+    }
+    server, requested := fakeHackerNews(t, ids, items)
+    defer server.Close()
+
+    scraper := NewHackerNews([]config.Source{
+        {Type: config.SourceHackerNews, URL: server.URL + "/v0/topstories.json", PageDepth: 1},
+    }).WithSeenChecker(seenSet{"hn:1": true, "hn:2": true})
+
+    posts, err := scraper.FetchPosts(context.Background())
+    require.NoError(t, err)
+    assert.Len(t, posts, hnPageSize-2)
+
+    for _, path := range requested() {
+        assert.NotEqual(t, "/v0/item/1.json", path)
+        assert.NotEqual(t, fmt.Sprintf("/v0/item/%d.json", hnPageSize+1), path)
+    }
+}
+
+func TestHackerNewsScraper_ListError(t *testing.T) {
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.WriteHeader(http.StatusInternalServerError)
+    }))
+    defer server.Close()
+
+    scraper := NewHackerNews([]config.Source{
+        {Type: config.SourceHackerNews, URL: server.URL + "/v0/beststories.json"},
+    })
+    _, err := scraper.FetchPosts(context.Background())
+    assert.Error(t, err)
+}
+
+func TestHNItemBase(t *testing.T) {
+    base, err := hnItemBase("https://hacker-news.firebaseio.com/v0/topstories.json")
+    require.NoError(t, err)
+    assert.Equal(t, "https://hacker-news.firebaseio.com/v0", base)
+
+    _, err = hnItemBase("https://news.ycombinator.com/")
+    assert.Error(t, err)
+}
+
+func TestMatchesKeywords(t *testing.T) {
+    _, items := hnStories()
+    post := *items[101].toPost()
+
+    assert.True(t, matchesKeywords(post, nil))
+    assert.True(t, matchesKeywords(post, []string{"inference"}))
+    assert.True(t, matchesKeywords(post, []string{"  LLM "}))
+    assert.False(t, matchesKeywords(post, []string{"typewriter", ""}))
+}
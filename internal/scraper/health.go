@@ -0,0 +1,130 @@
+package scraper
+
+import (
+    "context"
+    "fmt"
+    "log"
+    "sync"
+    "time"
+
+    "github.com/w1zzzle/ai-newsbot/internal/storage"
+)
+
+// SourceRunStore keeps what each source yielded across runs
+type SourceRunStore interface {
+    RecordSourceRun(ctx context.Context, run storage.SourceRun) error
+    RecentSourceRuns(ctx context.Context, source string, n int) ([]storage.SourceRun, error)
+}
+
+// Degradation explains why a source looks broken
+type Degradation struct {
+    Source string
+    Reason string
+}
+
+// HealthMonitor flags sources that still answer but no longer yield posts,
+// which usually means the site changed its markup
+type HealthMonitor struct {
+    store SourceRunStore
+    // History is how many past runs make up a source's baseline
+    History int
+    // BusyAverage is the average yield above which a source is expected to
+    // produce posts every run
+    BusyAverage float64
+    // MaxSkippedRatio is the share of containers that may fail extraction
+    MaxSkippedRatio float64
+
+    mu       sync.Mutex
+    degraded map[string]bool
+}
+
+// NewHealthMonitor creates a monitor backed by store
+func NewHealthMonitor(store SourceRunStore) *HealthMonitor {
+    return &HealthMonitor{
+        store:           store,
+        History:         10,
+        BusyAverage:     5,
+        MaxSkippedRatio: 0.5,
+        degraded:        make(map[string]bool),
+    }
+}
+
+// Check records the results of a run and returns the sources that became
+// degraded with it. Sources that stay degraded are reported only once.
+func (m *HealthMonitor) Check(ctx context.Context, results []SourceResult) ([]Degradation, error) {
+    var found []Degradation
+    now := time.Now()
+
+    for _, result := range results {
+        // Failures are tracked by FailureTracker, and a 304 says nothing
+        // about the markup
+        if result.Err != nil || result.NotModified {
+            continue
+        }
+
+        history, err := m.store.RecentSourceRuns(ctx, result.Source, m.History)
+        if err != nil {
+            return found, fmt.Errorf("failed to load runs of %s: %w", result.Source, err)
+        }
+
+        run := storage.SourceRun{
+            Source:     result.Source,
+            Posts:      len(result.Posts) + result.BelowThreshold + result.Filtered,
+            Containers: result.Containers,
+            Skipped:    result.Skipped,
+            RanAt:      now,
+        }
+        if err := m.store.RecordSourceRun(ctx, run); err != nil {
+            return found, fmt.Errorf("failed to record run of %s: %w", result.Source, err)
+        }
+
+        reason := m.diagnose(run, history)
+        if m.transition(result.Source, reason != "", run.Posts == 0) {
+            found = append(found, Degradation{Source: result.Source, Reason: reason})
+        }
+    }
+
+    return found, nil
+}
+
+// diagnose returns why run looks broken compared to history, or "" if it
+// looks healthy
+func (m *HealthMonitor) diagnose(run storage.SourceRun, history []storage.SourceRun) string {
+    if run.Containers > 0 && float64(run.Skipped) > m.MaxSkippedRatio*float64(run.Containers) {
+        return fmt.Sprintf("%d of %d post elements failed to extract", run.Skipped, run.Containers)
+    }
+
+    // A new source has no baseline yet
+    if run.Posts > 0 || len(history) < m.History/2 {
+        return ""
+    }
+
+    total := 0
+    for _, past := range history {
+        total += past.Posts
+    }
+    average := float64(total) / float64(len(history))
+    if average < m.BusyAverage {
+        return ""
+    }
+    return fmt.Sprintf("yielded no posts, averaged %.1f over the last %d runs", average, len(history))
+}
+
+// transition records whether source is degraded and reports whether it
+// just became so
+func (m *HealthMonitor) transition(source string, degraded, empty bool) bool {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    was := m.degraded[source]
+    // Empty runs wear down the baseline, so a degraded source only
+    // recovers once it yields posts again
+    if was && empty {
+        return false
+    }
+    m.degraded[source] = degraded
+    if was && !degraded {
+        log.Printf("Source %s recovered", source)
+    }
+    return degraded && !was
+}
@@ -0,0 +1,110 @@
+package scraper
+
+import (
+    "context"
+    "fmt"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "github.com/w1zzzle/ai-newsbot/internal/storage"
+)
+
+// memoryRunStore keeps source runs in memory, newest last
+type memoryRunStore struct {
+    runs map[string][]storage.SourceRun
+}
+
+func newMemoryRunStore() *memoryRunStore {
+    return &memoryRunStore{runs: make(map[string][]storage.SourceRun)}
+}
+
+func (m *memoryRunStore) RecordSourceRun(ctx context.Context, run storage.SourceRun) error {
+    m.runs[run.Source] = append(m.runs[run.Source], run)
+    return nil
+}
+
+func (m *memoryRunStore) RecentSourceRuns(ctx context.Context, source string, n int) ([]storage.SourceRun, error) {
+    var recent []storage.SourceRun
+    runs := m.runs[source]
+    for i := len(runs) - 1; i >= 0 && len(recent) < n; i-- {
+        recent = append(recent, runs[i])
+    }
+    return recent, nil
+}
+
+func postsResult(source string, n int) SourceResult {
+    result := SourceResult{Source: source}
+    for i := 0; i < n; i++ {
+        result.Posts = append(result.Posts, storage.Post{RedditID: fmt.Sprintf("%s-%d", source, i)})
+    }
+    return result
+}
+
+func TestHealthMonitor_BusySourceYieldsNothing(t *testing.T) {
+    store := newMemoryRunStore()
+    monitor := NewHealthMonitor(store)
+    ctx := context.Background()
+
+    for i := 0; i < 5; i++ {
+        degraded, err := monitor.Check(ctx, []SourceResult{postsResult("busy", 20), postsResult("quiet", 1)})
+        require.NoError(t, err)
+        assert.Empty(t, degraded)
+    }
+
+    degraded, err := monitor.Check(ctx, []SourceResult{postsResult("busy", 0), postsResult("quiet", 0)})
+    require.NoError(t, err)
+    require.Len(t, degraded, 1)
+    assert.Equal(t, "busy", degraded[0].Source)
+    assert.Contains(t, degraded[0].Reason, "yielded no posts")
+
+    // Still broken: no repeated alert, even as the baseline fades
+    for i := 0; i < 10; i++ {
+        degraded, err = monitor.Check(ctx, []SourceResult{postsResult("busy", 0)})
+        require.NoError(t, err)
+        assert.Empty(t, degraded)
+    }
+
+    // Recovers once it yields posts again
+    degraded, err = monitor.Check(ctx, []SourceResult{postsResult("busy", 20)})
+    require.NoError(t, err)
+    assert.Empty(t, degraded)
+    assert.Len(t, store.runs["busy"], 17)
+}
+
+func TestHealthMonitor_NoBaselineYet(t *testing.T) {
+    monitor := NewHealthMonitor(newMemoryRunStore())
+    ctx := context.Background()
+
+    _, err := monitor.Check(ctx, []SourceResult{postsResult("new", 30)})
+    require.NoError(t, err)
+
+    degraded, err := monitor.Check(ctx, []SourceResult{postsResult("new", 0)})
+    require.NoError(t, err)
+    assert.Empty(t, degraded)
+}
+
+func TestHealthMonitor_MostContainersFail(t *testing.T) {
+    monitor := NewHealthMonitor(newMemoryRunStore())
+
+    result := postsResult("reddit", 3)
+    result.Containers = 25
+    result.Skipped = 22
+
+    degraded, err := monitor.Check(context.Background(), []SourceResult{result})
+    require.NoError(t, err)
+    require.Len(t, degraded, 1)
+    assert.Equal(t, "22 of 25 post elements failed to extract", degraded[0].Reason)
+}
+
+func TestHealthMonitor_IgnoresFailedAndNotModified(t *testing.T) {
+    store := newMemoryRunStore()
+    monitor := NewHealthMonitor(store)
+
+    _, err := monitor.Check(context.Background(), []SourceResult{
+        {Source: "down", Err: fmt.Errorf("HTTP 500")},
+        {Source: "cached", NotModified: true},
+    })
+    require.NoError(t, err)
+    assert.Empty(t, store.runs)
+}
@@ -0,0 +1,214 @@
+package scraper
+
+import (
+    "context"
+    "crypto/sha1"
+    "encoding/hex"
+    "fmt"
+    "net/http"
+    "net/url"
+    "strings"
+    "time"
+
+    "github.com/PuerkitoBio/goquery"
+    "github.com/w1zzzle/ai-newsbot/internal/config"
+    "github.com/w1zzzle/ai-newsbot/internal/storage"
+)
+
+// HTMLScraper extracts posts from arbitrary pages with the CSS selectors
+// configured for each source, so news sites can be added without code
+type HTMLScraper struct {
+    sources []config.Source
+    http    *fetcher
+}
+
+// Extraction is what the selectors of a source found on a page
+type Extraction struct {
+    Posts []storage.Post
+    // Containers is how many elements matched the container selector
+    Containers int
+    // Skipped is how many containers yielded no post, usually because the
+    // title selector matched nothing
+    Skipped int
+}
+
+// NewHTML creates a scraper for selector-based html sources
+func NewHTML(sources []config.Source, opts ...Option) *HTMLScraper {
+    return &HTMLScraper{
+        sources: sources,
+        http:    newFetcher(opts...),
+    }
+}
+
+func (s *HTMLScraper) FetchPosts(ctx context.Context) ([]storage.Post, error) {
+    return collectPosts(s.FetchResults(ctx))
+}
+
+func (s *HTMLScraper) FetchResults(ctx context.Context) []SourceResult {
+    return s.http.fetchConcurrently(ctx, s.sources, func(ctx context.Context, source config.Source) SourceResult {
+        extraction, err := s.Extract(ctx, source)
+        return extractionResult(source, extraction, err)
+    })
+}
+
+// Extract fetches the source's page and applies its selectors
+func (s *HTMLScraper) Extract(ctx context.Context, source config.Source) (Extraction, error) {
+    resp, err := s.http.get(ctx, source.URL, nil)
+    if err != nil {
+        return Extraction{}, err
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode != http.StatusOK {
+        return Extraction{}, fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
+    }
+
+    doc, err := goquery.NewDocumentFromReader(resp.Body)
+    if err != nil {
+        return Extraction{}, err
+    }
+
+    extraction := extractPage(doc, resp.Request.URL, source.Selectors)
+    s.http.remember(ctx, resp)
+    return extraction, nil
+}
+
+// extractPage applies selectors to a page. Relative links and media URLs
+// are resolved against base.
+func extractPage(doc *goquery.Document, base *url.URL, selectors config.HTMLSelectors) Extraction {
+    var extraction Extraction
+
+    doc.Find(selectors.Container).Each(func(i int, container *goquery.Selection) {
+        extraction.Containers++
+        post := extractSelected(container, base, selectors)
+        if post == nil {
+            extraction.Skipped++
+            return
+        }
+        extraction.Posts = append(extraction.Posts, *post)
+    })
+
+    return extraction
+}
+
+func extractSelected(container *goquery.Selection, base *url.URL, selectors config.HTMLSelectors) *storage.Post {
+    title := collapseSpace(selectValue(container, selectors.Title, ""))
+    if title == "" {
+        return nil
+    }
+
+    link := resolveURL(base, selectValue(container, selectors.Link, "href"))
+
+    id := strings.TrimSpace(selectValue(container, selectors.ID, ""))
+    if id == "" {
+        id = link
+    }
+    if id == "" {
+        id = title
+    }
+
+    var paragraphs []string
+    selectEach(container, selectors.Body, "", func(text string) {
+        if text != "" {
+            paragraphs = append(paragraphs, text)
+        }
+    })
+
+    var mediaURLs []string
+    selectEach(container, selectors.Media, "src", func(value string) {
+        if u := resolveURL(base, value); strings.HasPrefix(u, "http") {
+            mediaURLs = append(mediaURLs, u)
+        }
+    })
+
+    score, _ := parseScore(selectValue(container, selectors.Score, ""))
+
+    createdAt := time.Now()
+    if date := strings.TrimSpace(selectValue(container, selectors.Date, "")); date != "" {
+        createdAt = parseFeedTime(date)
+    }
+
+    return &storage.Post{
+        RedditID:  htmlPostID(base.Host, id),
+        Title:     title,
+        Body:      strings.Join(paragraphs, "\n\n"),
+        Score:     score,
+        Permalink: link,
+        MediaURLs: mediaURLs,
+        CreatedAt: createdAt,
+    }
+}
+
+// splitSelector splits "a.title@href" into its CSS selector and attribute
+func splitSelector(spec string) (string, string) {
+    if i := strings.LastIndex(spec, "@"); i >= 0 {
+        return strings.TrimSpace(spec[:i]), strings.TrimSpace(spec[i+1:])
+    }
+    return strings.TrimSpace(spec), ""
+}
+
+// selectEach calls fn with the value of every element matching spec inside
+// container. Elements are read through defaultAttr when spec names no
+// attribute and the element has it, and as plain text otherwise.
+func selectEach(container *goquery.Selection, spec, defaultAttr string, fn func(string)) {
+    if spec == "" {
+        return
+    }
+
+    css, attr := splitSelector(spec)
+    matches := container
+    if css != "" {
+        matches = container.Find(css)
+    }
+
+    matches.Each(func(i int, el *goquery.Selection) {
+        if attr != "" {
+            if value, ok := el.Attr(attr); ok {
+                fn(value)
+            }
+            return
+        }
+        if defaultAttr != "" {
+            if value, ok := el.Attr(defaultAttr); ok {
+                fn(value)
+                return
+            }
+        }
+        html, err := el.Html()
+        if err != nil {
+            return
+        }
+        fn(stripHTML(html))
+    })
+}
+
+// selectValue returns the value of the first element matching spec
+func selectValue(container *goquery.Selection, spec, defaultAttr string) string {
+    var value string
+    found := false
+    selectEach(container, spec, defaultAttr, func(v string) {
+        if !found {
+            value = v
+            found = true
+        }
+    })
+    return value
+}
+
+func resolveURL(base *url.URL, ref string) string {
+    ref = strings.TrimSpace(ref)
+    if ref == "" {
+        return ""
+    }
+    u, err := base.Parse(ref)
+    if err != nil {
+        return ""
+    }
+    return u.String()
+}
+
+// htmlPostID namespaces a post's ID by the site it came from
+func htmlPostID(host, id string) string {
+    sum := sha1.Sum([]byte(host + "\n" + id))
+    return "html:" + hex.EncodeToString(sum[:])[:16]
+}
@@ -0,0 +1,110 @@
+package scraper
+
+import (
+    "context"
+    "net/http"
+    "net/http/httptest"
+    "net/url"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "github.com/w1zzzle/ai-newsbot/internal/config"
+)
+
+var newsSelectors = config.HTMLSelectors{
+    Container: "article.story",
+    ID:        "@data-id",
+    Title:     "h2.headline",
+    Body:      "div.summary p",
+    Link:      "h2.headline a",
+    Media:     "img.thumb",
+    Score:     "span.points",
+    Date:      "time@datetime",
+}
+
+func newsServer(t *testing.T) *httptest.Server {
+    t.Helper()
+    page := readFixture(t, "news_page.html")
+    return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.Header().Set("Content-Type", "text/html")
+        w.Write(page)
+    }))
+}
+
+func TestHTMLScraper_Extract(t *testing.T) {
+    server := newsServer(t)
+    defer server.Close()
+
+    source := config.Source{Type: config.SourceHTML, URL: server.URL + "/news/", Selectors: newsSelectors}
+    extraction, err := NewHTML(nil).Extract(context.Background(), source)
+    require.NoError(t, err)
+
+    assert.Equal(t, 3, extraction.Containers)
+    assert.Equal(t, 1, extraction.Skipped)
+    require.Len(t, extraction.Posts, 2)
+
+    post := extraction.Posts[0]
+    assert.Regexp(t, `^html:[0-9a-f]{16}$`, post.RedditID)
+    assert.Equal(t, "Open weights model tops leaderboard", post.Title)
+    assert.Equal(t, "A new open model leads the arena.\n\nWeights are on the hub.", post.Body)
+    assert.Equal(t, server.URL+"/2024/03/open-weights", post.Permalink)
+    assert.Equal(t, []string{server.URL + "/img/leaderboard.png"}, post.MediaURLs)
+    assert.Equal(t, 1200, post.Score)
+    assert.Equal(t, time.Date(2024, 3, 12, 15, 0, 0, 0, time.UTC), post.CreatedAt)
+
+    assert.Equal(t, "https://other.example.org/chips", extraction.Posts[1].Permalink)
+    assert.Equal(t, 87, extraction.Posts[1].Score)
+    assert.NotEqual(t, post.RedditID, extraction.Posts[1].RedditID)
+}
+
+func TestHTMLScraper_FetchResultsAppliesThreshold(t *testing.T) {
+    server := newsServer(t)
+    defer server.Close()
+
+    scraper := NewHTML([]config.Source{
+        {Type: config.SourceHTML, URL: server.URL, Selectors: newsSelectors, UpvoteThreshold: 100},
+    })
+    results := scraper.FetchResults(context.Background())
+    require.Len(t, results, 1)
+    require.NoError(t, results[0].Err)
+
+    assert.Len(t, results[0].Posts, 1)
+    assert.Equal(t, 1, results[0].BelowThreshold)
+}
+
+func TestHTMLScraper_IDFallsBackToLink(t *testing.T) {
+    server := newsServer(t)
+    defer server.Close()
+
+    selectors := newsSelectors
+    selectors.ID = ""
+    source := config.Source{Type: config.SourceHTML, URL: server.URL, Selectors: selectors}
+
+    first, err := NewHTML(nil).Extract(context.Background(), source)
+    require.NoError(t, err)
+    second, err := NewHTML(nil).Extract(context.Background(), source)
+    require.NoError(t, err)
+
+    base, err := url.Parse(server.URL)
+    require.NoError(t, err)
+
+    require.Len(t, first.Posts, 2)
+    assert.Equal(t, htmlPostID(base.Host, server.URL+"/2024/03/open-weights"), first.Posts[0].RedditID)
+    assert.Equal(t, postIDs(first.Posts), postIDs(second.Posts))
+}
+
+func TestSplitSelector(t *testing.T) {
+    css, attr := splitSelector("a.title@href")
+    assert.Equal(t, "a.title", css)
+    assert.Equal(t, "href", attr)
+
+    css, attr = splitSelector("@data-id")
+    assert.Equal(t, "", css)
+    assert.Equal(t, "data-id", attr)
+
+    css, attr = splitSelector("h2 > span")
+    assert.Equal(t, "h2 > span", css)
+    assert.Equal(t, "", attr)
+}
@@ -0,0 +1,338 @@
+package scraper
+
+import (
+    "context"
+    "fmt"
+    "log"
+    "net/http"
+    "strconv"
+    "sync"
+    "time"
+
+    "github.com/w1zzzle/ai-newsbot/internal/config"
+)
+
+const defaultUserAgent = "Mozilla/5.0 (compatible; AI-NewsBot/1.0)"
+
+// fetcher is the HTTP layer shared by all scrapers. It limits the request
+// rate per host, retries throttled responses and applies the HTTP settings
+// of the source being fetched.
+type fetcher struct {
+    client       *http.Client
+    transport    *sourceTransport
+    userAgent    string
+    limiter      *hostLimiter
+    validators   *validatorCache
+    workers      int
+    maxRetries   int
+    retryBase    time.Duration
+    maxRetryWait time.Duration
+}
+
+// Option configures the HTTP layer of a scraper
+type Option func(*fetcher)
+
+// WithWorkers sets how many sources are fetched concurrently
+func WithWorkers(n int) Option {
+    return func(f *fetcher) {
+        if n > 0 {
+            f.workers = n
+        }
+    }
+}
+
+// WithHostRateLimit allows perMinute requests per host with bursts of up
+// to burst requests. A zero rate disables limiting.
+func WithHostRateLimit(perMinute float64, burst int) Option {
+    return func(f *fetcher) {
+        f.limiter = newHostLimiter(perMinute/60, burst)
+    }
+}
+
+// WithMaxRetries sets how many times a 429/503 response is retried
+func WithMaxRetries(n int) Option {
+    return func(f *fetcher) {
+        f.maxRetries = n
+    }
+}
+
+func newFetcher(opts ...Option) *fetcher {
+    transport := newSourceTransport()
+    f := &fetcher{
+        client: &http.Client{
+            Timeout:   30 * time.Second,
+            Transport: transport,
+        },
+        transport:    transport,
+        userAgent:    defaultUserAgent,
+        limiter:      newHostLimiter(0, 0),
+        validators:   newValidatorCache(),
+        workers:      4,
+        maxRetries:   2,
+        retryBase:    2 * time.Second,
+        maxRetryWait: time.Minute,
+    }
+    for _, opt := range opts {
+        opt(f)
+    }
+    return f
+}
+
+// get performs a conditional GET request, waiting for the host's rate limit
+// and backing off on 429/503 responses. A source with a proxy pool moves
+// on to its next proxy on 403/429 before backing off. A 304 response is
+// reported as errNotModified. The caller owns the returned response body.
+func (f *fetcher) get(ctx context.Context, url string, header http.Header) (*http.Response, error) {
+    source, _ := sourceFrom(ctx)
+    rotations := 0
+
+    for attempt := 0; ; {
+        req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+        if err != nil {
+            return nil, err
+        }
+
+        // Set User-Agent to avoid being blocked
+        req.Header.Set("User-Agent", f.userAgent)
+        if source.HTTP.UserAgent != "" {
+            req.Header.Set("User-Agent", source.HTTP.UserAgent)
+        }
+        // Headers of the source come first so a scraper's Accept still wins
+        for key, value := range source.HTTP.Headers {
+            req.Header.Set(key, value)
+        }
+        for key, values := range header {
+            req.Header[key] = values
+        }
+        f.validators.addConditionalHeaders(req)
+
+        if err := f.limiter.wait(ctx, req.URL.Host); err != nil {
+            return nil, err
+        }
+
+        resp, err := f.client.Do(req)
+        if err != nil {
+            return nil, err
+        }
+
+        if resp.StatusCode == http.StatusNotModified {
+            resp.Body.Close()
+            return nil, errNotModified
+        }
+
+        // Blocks are usually tied to the proxy's address, so waiting
+        // would not help until every proxy has been tried
+        blocked := resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests
+        if blocked && rotations < f.transport.poolSize(ctx)-1 && f.transport.rotate(ctx) {
+            resp.Body.Close()
+            rotations++
+            continue
+        }
+
+        if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+            return resp, nil
+        }
+
+        delay, ok := retryAfter(resp.Header.Get("Retry-After"), time.Now())
+        if !ok {
+            delay = f.retryBase << attempt
+        }
+
+        // Hold back every request to this host, not just this one
+        f.limiter.pause(req.URL.Host, min(delay, f.maxRetryWait))
+
+        if attempt >= f.maxRetries || delay > f.maxRetryWait {
+            return resp, nil
+        }
+        resp.Body.Close()
+
+        log.Printf("%s returned HTTP %d, retrying in %s", url, resp.StatusCode, delay)
+        if err := sleep(ctx, delay); err != nil {
+            return nil, err
+        }
+        attempt++
+    }
+}
+
+// fetchConcurrently runs fetch for every source on a bounded worker pool.
+// Results keep the order of sources and hold back the cache validators of
+// their responses until committed.
+func (f *fetcher) fetchConcurrently(ctx context.Context, sources []config.Source, fetch func(context.Context, config.Source) SourceResult) []SourceResult {
+    results := make([]SourceResult, len(sources))
+    jobs := make(chan int)
+
+    var wg sync.WaitGroup
+    for w := 0; w < f.workers && w < len(sources); w++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            for i := range jobs {
+                pending := &pendingValidators{}
+                sourceCtx := withPendingValidators(withSource(ctx, sources[i]), pending)
+                results[i] = fetch(sourceCtx, sources[i])
+                results[i].validators = pending
+            }
+        }()
+    }
+
+    for i := range sources {
+        jobs <- i
+    }
+    close(jobs)
+    wg.Wait()
+
+    return results
+}
+
+// forEach calls fn for 0..n-1 on the same bounded worker pool size as
+// sources, for scrapers that fetch many items per source
+func (f *fetcher) forEach(n int, fn func(i int)) {
+    jobs := make(chan int)
+
+    var wg sync.WaitGroup
+    for w := 0; w < f.workers && w < n; w++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            for i := range jobs {
+                fn(i)
+            }
+        }()
+    }
+
+    for i := 0; i < n; i++ {
+        jobs <- i
+    }
+    close(jobs)
+    wg.Wait()
+}
+
+// retryAfter parses a Retry-After header given either in seconds or as an
+// HTTP date
+func retryAfter(value string, now time.Time) (time.Duration, bool) {
+    if value == "" {
+        return 0, false
+    }
+
+    if seconds, err := strconv.Atoi(value); err == nil {
+        if seconds < 0 {
+            return 0, false
+        }
+        return time.Duration(seconds) * time.Second, true
+    }
+
+    if at, err := http.ParseTime(value); err == nil {
+        delay := at.Sub(now)
+        if delay < 0 {
+            delay = 0
+        }
+        return delay, true
+    }
+
+    return 0, false
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+    if d <= 0 {
+        return nil
+    }
+
+    timer := time.NewTimer(d)
+    defer timer.Stop()
+
+    select {
+    case <-timer.C:
+        return nil
+    case <-ctx.Done():
+        return ctx.Err()
+    }
+}
+
+// hostLimiter keeps a token bucket per host
+type hostLimiter struct {
+    rate  float64 // tokens per second, zero disables limiting
+    burst float64
+
+    mu      sync.Mutex
+    buckets map[string]*tokenBucket
+}
+
+type tokenBucket struct {
+    tokens      float64
+    last        time.Time
+    pausedUntil time.Time
+}
+
+func newHostLimiter(rate float64, burst int) *hostLimiter {
+    if burst < 1 {
+        burst = 1
+    }
+    return &hostLimiter{
+        rate:    rate,
+        burst:   float64(burst),
+        buckets: make(map[string]*tokenBucket),
+    }
+}
+
+func (l *hostLimiter) bucket(host string, now time.Time) *tokenBucket {
+    b, ok := l.buckets[host]
+    if !ok {
+        b = &tokenBucket{tokens: l.burst, last: now}
+        l.buckets[host] = b
+    }
+    return b
+}
+
+// wait blocks until a request to host is allowed
+func (l *hostLimiter) wait(ctx context.Context, host string) error {
+    for {
+        delay := l.reserve(host, time.Now())
+        if delay == 0 {
+            return nil
+        }
+        if err := sleep(ctx, delay); err != nil {
+            return fmt.Errorf("waiting for rate limit on %s: %w", host, err)
+        }
+    }
+}
+
+// reserve takes a token for host if one is available and otherwise returns
+// how long to wait before trying again
+func (l *hostLimiter) reserve(host string, now time.Time) time.Duration {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+
+    b := l.bucket(host, now)
+    if now.Before(b.pausedUntil) {
+        return b.pausedUntil.Sub(now)
+    }
+
+    if l.rate <= 0 {
+        return 0
+    }
+
+    b.tokens += now.Sub(b.last).Seconds() * l.rate
+    if b.tokens > l.burst {
+        b.tokens = l.burst
+    }
+    b.last = now
+
+    if b.tokens >= 1 {
+        b.tokens--
+        return 0
+    }
+
+    return time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+}
+
+// pause stops all requests to host for d
+func (l *hostLimiter) pause(host string, d time.Duration) {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+
+    now := time.Now()
+    b := l.bucket(host, now)
+    if until := now.Add(d); until.After(b.pausedUntil) {
+        b.pausedUntil = until
+    }
+}
@@ -0,0 +1,146 @@
+package scraper
+
+import (
+    "context"
+    "net/http"
+    "net/http/httptest"
+    "sync/atomic"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "github.com/w1zzzle/ai-newsbot/internal/config"
+)
+
+func TestFetcher_Get_HonorsRetryAfter(t *testing.T) {
+    var calls int32
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if atomic.AddInt32(&calls, 1) == 1 {
+            w.Header().Set("Retry-After", "1")
+            w.WriteHeader(http.StatusTooManyRequests)
+            return
+        }
+        w.WriteHeader(http.StatusOK)
+    }))
+    defer server.Close()
+
+    f := newFetcher()
+    start := time.Now()
+    resp, err := f.get(context.Background(), server.URL, nil)
+    require.NoError(t, err)
+    defer resp.Body.Close()
+
+    assert.Equal(t, http.StatusOK, resp.StatusCode)
+    assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+    assert.GreaterOrEqual(t, time.Since(start), time.Second)
+}
+
+func TestFetcher_Get_BacksOffOnServiceUnavailable(t *testing.T) {
+    var calls int32
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        atomic.AddInt32(&calls, 1)
+        w.WriteHeader(http.StatusServiceUnavailable)
+    }))
+    defer server.Close()
+
+    f := newFetcher(WithMaxRetries(2))
+    f.retryBase = 10 * time.Millisecond
+
+    resp, err := f.get(context.Background(), server.URL, nil)
+    require.NoError(t, err)
+    defer resp.Body.Close()
+
+    // The last throttled response is handed back to the caller
+    assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+    assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestFetcher_Get_GivesUpOnLongRetryAfter(t *testing.T) {
+    var calls int32
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        atomic.AddInt32(&calls, 1)
+        w.Header().Set("Retry-After", "3600")
+        w.WriteHeader(http.StatusTooManyRequests)
+    }))
+    defer server.Close()
+
+    f := newFetcher()
+    f.maxRetryWait = 10 * time.Millisecond
+
+    resp, err := f.get(context.Background(), server.URL, nil)
+    require.NoError(t, err)
+    defer resp.Body.Close()
+
+    assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+    assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestFetcher_FetchConcurrently(t *testing.T) {
+    f := newFetcher(WithWorkers(2))
+
+    var running, maxRunning int32
+    sources := []config.Source{{URL: "a"}, {URL: "b"}, {URL: "c"}, {URL: "d"}, {URL: "e"}}
+
+    results := f.fetchConcurrently(context.Background(), sources, func(ctx context.Context, source config.Source) SourceResult {
+        n := atomic.AddInt32(&running, 1)
+        for {
+            max := atomic.LoadInt32(&maxRunning)
+            if n <= max || atomic.CompareAndSwapInt32(&maxRunning, max, n) {
+                break
+            }
+        }
+        time.Sleep(20 * time.Millisecond)
+        atomic.AddInt32(&running, -1)
+        return SourceResult{Source: source.URL}
+    })
+
+    require.Len(t, results, 5)
+    for i, result := range results {
+        assert.Equal(t, sources[i].URL, result.Source)
+    }
+    assert.LessOrEqual(t, atomic.LoadInt32(&maxRunning), int32(2))
+}
+
+func TestHostLimiter_Reserve(t *testing.T) {
+    limiter := newHostLimiter(1, 2) // one request per second, bursts of two
+    now := time.Date(2024, 3, 11, 12, 0, 0, 0, time.UTC)
+
+    assert.Zero(t, limiter.reserve("www.reddit.com", now))
+    assert.Zero(t, limiter.reserve("www.reddit.com", now))
+    assert.Equal(t, time.Second, limiter.reserve("www.reddit.com", now))
+
+    // Other hosts have their own bucket
+    assert.Zero(t, limiter.reserve("news.ycombinator.com", now))
+
+    // Tokens refill over time
+    assert.Zero(t, limiter.reserve("www.reddit.com", now.Add(time.Second)))
+}
+
+func TestHostLimiter_Pause(t *testing.T) {
+    limiter := newHostLimiter(0, 0)
+
+    assert.Zero(t, limiter.reserve("www.reddit.com", time.Now()))
+
+    limiter.pause("www.reddit.com", time.Minute)
+    assert.Greater(t, limiter.reserve("www.reddit.com", time.Now()), 59*time.Second)
+    assert.Zero(t, limiter.reserve("old.reddit.com", time.Now()))
+}
+
+func TestRetryAfter(t *testing.T) {
+    now := time.Date(2024, 3, 11, 12, 0, 0, 0, time.UTC)
+
+    delay, ok := retryAfter("120", now)
+    assert.True(t, ok)
+    assert.Equal(t, 2*time.Minute, delay)
+
+    delay, ok = retryAfter("Mon, 11 Mar 2024 12:00:30 GMT", now)
+    assert.True(t, ok)
+    assert.Equal(t, 30*time.Second, delay)
+
+    _, ok = retryAfter("", now)
+    assert.False(t, ok)
+
+    _, ok = retryAfter("soon", now)
+    assert.False(t, ok)
+}
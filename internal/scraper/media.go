@@ -0,0 +1,184 @@
+package scraper
+
+import (
+    "html"
+    "net/url"
+    "path"
+    "strings"
+
+    "github.com/w1zzzle/ai-newsbot/internal/storage"
+)
+
+// redditMediaEmbed is the media/secure_media object of a submission
+type redditMediaEmbed struct {
+    RedditVideo *redditVideo `json:"reddit_video"`
+}
+
+type redditVideo struct {
+    FallbackURL string `json:"fallback_url"`
+    Width       int    `json:"width"`
+    Height      int    `json:"height"`
+    IsGIF       bool   `json:"is_gif"`
+}
+
+type redditPreview struct {
+    Images []struct {
+        Source redditImage `json:"source"`
+    } `json:"images"`
+    // RedditVideoPreview is Reddit's transcode of an external GIF or video
+    RedditVideoPreview *redditVideo `json:"reddit_video_preview"`
+}
+
+type redditImage struct {
+    URL    string `json:"url"`
+    Width  int    `json:"width"`
+    Height int    `json:"height"`
+}
+
+// redditMediaMetadata describes one gallery item. Its source uses
+// single-letter keys: u is the image, gif and mp4 the animated versions,
+// x and y the dimensions.
+type redditMediaMetadata struct {
+    Status string `json:"status"`
+    // E is "Image" or "AnimatedImage"
+    E string `json:"e"`
+    S struct {
+        U   string `json:"u"`
+        GIF string `json:"gif"`
+        MP4 string `json:"mp4"`
+        X   int    `json:"x"`
+        Y   int    `json:"y"`
+    } `json:"s"`
+}
+
+var imageExtensions = map[string]string{
+    ".jpg":  storage.MediaImage,
+    ".jpeg": storage.MediaImage,
+    ".png":  storage.MediaImage,
+    ".webp": storage.MediaImage,
+    ".gif":  storage.MediaGIF,
+}
+
+// resolveMedia returns the media of a submission, preferring the richest
+// form Reddit offers: a gallery, hosted video, a direct image link and
+// finally the preview image
+func (d redditPostData) resolveMedia() []storage.Media {
+    if d.IsGallery {
+        if media := d.galleryMedia(); len(media) > 0 {
+            return media
+        }
+    }
+
+    for _, embed := range []*redditMediaEmbed{d.Media, d.SecureMedia} {
+        if embed != nil && embed.RedditVideo != nil {
+            if media, ok := embed.RedditVideo.toMedia(); ok {
+                return []storage.Media{media}
+            }
+        }
+    }
+
+    if d.Preview != nil && d.Preview.RedditVideoPreview != nil {
+        if media, ok := d.Preview.RedditVideoPreview.toMedia(); ok {
+            return []storage.Media{media}
+        }
+    }
+
+    if media, ok := d.linkedImage(); ok {
+        return []storage.Media{media}
+    }
+
+    if d.Preview != nil && len(d.Preview.Images) > 0 && !d.IsSelf {
+        source := d.Preview.Images[0].Source
+        if src := unescapeMediaURL(source.URL); strings.HasPrefix(src, "http") {
+            return []storage.Media{{URL: src, Type: storage.MediaImage, Width: source.Width, Height: source.Height}}
+        }
+    }
+
+    return nil
+}
+
+// galleryMedia returns gallery items in the order the poster chose;
+// media_metadata itself is an unordered map
+func (d redditPostData) galleryMedia() []storage.Media {
+    if d.GalleryData == nil {
+        return nil
+    }
+
+    var media []storage.Media
+    for _, item := range d.GalleryData.Items {
+        meta, ok := d.MediaMetadata[item.MediaID]
+        if !ok || meta.Status != "valid" {
+            continue
+        }
+
+        m := storage.Media{Type: storage.MediaImage, URL: meta.S.U, Width: meta.S.X, Height: meta.S.Y}
+        if meta.E == "AnimatedImage" {
+            m.Type = storage.MediaGIF
+            m.URL = meta.S.MP4
+            if m.URL == "" {
+                m.URL = meta.S.GIF
+            }
+        }
+        if m.URL = unescapeMediaURL(m.URL); strings.HasPrefix(m.URL, "http") {
+            media = append(media, m)
+        }
+    }
+    return media
+}
+
+// linkedImage recognises posts linking straight to an image, on Reddit's
+// own image host or an external one such as Imgur
+func (d redditPostData) linkedImage() (storage.Media, bool) {
+    u, err := url.Parse(d.URL)
+    if err != nil || !strings.HasPrefix(u.Scheme, "http") {
+        return storage.Media{}, false
+    }
+
+    ext := strings.ToLower(path.Ext(u.Path))
+    mediaType, ok := imageExtensions[ext]
+    switch {
+    case ext == ".gifv":
+        // Imgur serves .gifv pages; the clip itself is the .mp4
+        u.Path = strings.TrimSuffix(u.Path, path.Ext(u.Path)) + ".mp4"
+        mediaType = storage.MediaGIF
+    case ok:
+    case d.PostHint == "image":
+        mediaType = storage.MediaImage
+    default:
+        return storage.Media{}, false
+    }
+
+    media := storage.Media{URL: u.String(), Type: mediaType}
+    if d.Preview != nil && len(d.Preview.Images) > 0 {
+        media.Width = d.Preview.Images[0].Source.Width
+        media.Height = d.Preview.Images[0].Source.Height
+    }
+    return media, true
+}
+
+func (v *redditVideo) toMedia() (storage.Media, bool) {
+    if !strings.HasPrefix(v.FallbackURL, "http") {
+        return storage.Media{}, false
+    }
+
+    mediaType := storage.MediaVideo
+    if v.IsGIF {
+        mediaType = storage.MediaGIF
+    }
+    return storage.Media{URL: v.FallbackURL, Type: mediaType, Width: v.Width, Height: v.Height}, true
+}
+
+// unescapeMediaURL undoes the HTML escaping Reddit applies to media URLs
+// inside its JSON
+func unescapeMediaURL(raw string) string {
+    return html.UnescapeString(raw)
+}
+
+// mediaURLs lists the URLs of media in order
+func mediaURLs(media []storage.Media) []string {
+    var urls []string
+    for _, m := range media {
+        urls = append(urls, m.URL)
+    }
+    return urls
+}
@@ -0,0 +1,97 @@
+package scraper
+
+import (
+    "context"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "github.com/w1zzzle/ai-newsbot/internal/storage"
+)
+
+func TestJSONScraper_FetchPosts_ResolvesMedia(t *testing.T) {
+    server := httptest.NewServer(serveFixture(t, "listing_media.json"))
+    defer server.Close()
+
+    scraper := NewJSON([]string{server.URL + "/r/StableDiffusion/top/"}, 0)
+    posts, err := scraper.FetchPosts(context.Background())
+    require.NoError(t, err)
+    require.Len(t, posts, 5)
+
+    // Gallery items follow gallery_data order; failed items are dropped
+    assert.Equal(t, []storage.Media{
+        {URL: "https://preview.redd.it/zz2.jpg?width=1024&format=pjpg&s=22zz", Type: storage.MediaImage, Width: 1024, Height: 1536},
+        {URL: "https://preview.redd.it/aa1.png?width=1024&format=png&s=11aa", Type: storage.MediaImage, Width: 1024, Height: 768},
+        {URL: "https://preview.redd.it/gf3.gif?format=mp4&s=33gf", Type: storage.MediaGIF, Width: 640, Height: 480},
+    }, posts[0].Media)
+    assert.Len(t, posts[0].MediaURLs, 3)
+
+    // External image host, sized from the preview
+    assert.Equal(t, []storage.Media{
+        {URL: "https://i.imgur.com/Up5cAle.jpeg", Type: storage.MediaImage, Width: 2048, Height: 1024},
+    }, posts[1].Media)
+
+    // Reddit's transcode of an external GIF
+    assert.Equal(t, []storage.Media{
+        {URL: "https://v.redd.it/l4tent/DASH_480.mp4?source=fallback", Type: storage.MediaGIF, Width: 480, Height: 480},
+    }, posts[2].Media)
+
+    // Hosted video found under secure_media
+    assert.Equal(t, []storage.Media{
+        {URL: "https://v.redd.it/t1mel4pse/DASH_1080.mp4?source=fallback", Type: storage.MediaVideo, Width: 1920, Height: 1080},
+    }, posts[3].Media)
+
+    // Link post falls back to its preview image
+    assert.Equal(t, []storage.Media{
+        {URL: "https://external-preview.redd.it/0pEnW.jpg?width=1200&s=66ow", Type: storage.MediaImage, Width: 1200, Height: 630},
+    }, posts[4].Media)
+    assert.Equal(t, []string{"https://external-preview.redd.it/0pEnW.jpg?width=1200&s=66ow"}, posts[4].MediaURLs)
+}
+
+func TestRedditPostData_ResolveMedia_ImgurGifv(t *testing.T) {
+    data := redditPostData{URL: "https://i.imgur.com/AbCd.gifv"}
+    assert.Equal(t, []storage.Media{
+        {URL: "https://i.imgur.com/AbCd.mp4", Type: storage.MediaGIF},
+    }, data.resolveMedia())
+
+    assert.Empty(t, redditPostData{IsSelf: true, URL: "https://www.reddit.com/r/x/comments/1/"}.resolveMedia())
+}
+
+func TestUnescapeMediaURL(t *testing.T) {
+    assert.Equal(t,
+        "https://preview.redd.it/a1.jpg?width=640&format=pjpg&s=abc",
+        unescapeMediaURL("https://preview.redd.it/a1.jpg?width=640&amp;format=pjpg&amp;s&#x3D;abc"))
+    assert.Equal(t,
+        `https://external-preview.redd.it/b2.png?name="x"`,
+        unescapeMediaURL("https://external-preview.redd.it/b2.png?name=&quot;x&quot;"))
+}
+
+func TestRedditScraper_FetchPosts_SkipsDecorativeImages(t *testing.T) {
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.WriteHeader(http.StatusOK)
+        w.Write([]byte(`
+        <html><body>
+            <div data-testid="post-container" data-post-id="med1" score="500">
+                <img src="https://styles.redditmedia.com/t5_2r3gv/styles/communityIcon_x.png" alt="r/MachineLearning">
+                <img class="avatar" src="https://i.redd.it/snoovatar/avatars/u1.png">
+                <img src="https://www.redditstatic.com/gold/awards/icon/silver_64.png">
+                <h3 data-testid="post-title">Attention heatmaps</h3>
+                <img src="https://i.redd.it/h34tm4p.png" width="1600" height="900">
+                <img src="https://i.redd.it/h34tm4p.png" width="1600" height="900">
+                <video><source src="https://v.redd.it/d3m0/DASH_720.mp4"></video>
+            </div>
+        </body></html>`))
+    }))
+    defer server.Close()
+
+    posts, err := New([]string{server.URL}, 100).FetchPosts(context.Background())
+    require.NoError(t, err)
+    require.Len(t, posts, 1)
+
+    assert.Equal(t, []storage.Media{
+        {URL: "https://i.redd.it/h34tm4p.png", Type: storage.MediaImage, Width: 1600, Height: 900},
+        {URL: "https://v.redd.it/d3m0/DASH_720.mp4", Type: storage.MediaVideo},
+    }, posts[0].Media)
+}
@@ -0,0 +1,55 @@
+package scraper
+
+import (
+    "context"
+    "fmt"
+    "sync"
+
+    "github.com/w1zzzle/ai-newsbot/internal/storage"
+)
+
+// Multi combines the scrapers for different source types into one
+type Multi struct {
+    scrapers []Scraper
+}
+
+// NewMulti creates a scraper that runs every given scraper concurrently
+func NewMulti(scrapers ...Scraper) *Multi {
+    return &Multi{scrapers: scrapers}
+}
+
+func (m *Multi) FetchPosts(ctx context.Context) ([]storage.Post, error) {
+    return collectPosts(m.FetchResults(ctx))
+}
+
+// FetchResults returns the per-source results of all scrapers, in the order
+// the scrapers were given
+func (m *Multi) FetchResults(ctx context.Context) []SourceResult {
+    results := make([][]SourceResult, len(m.scrapers))
+
+    var wg sync.WaitGroup
+    for i, s := range m.scrapers {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            results[i] = fetchResults(ctx, s)
+        }()
+    }
+    wg.Wait()
+
+    var all []SourceResult
+    for _, r := range results {
+        all = append(all, r...)
+    }
+    return all
+}
+
+// fetchResults reports a scraper without per-source results as a single
+// source named after its type
+func fetchResults(ctx context.Context, s Scraper) []SourceResult {
+    if rf, ok := s.(ResultFetcher); ok {
+        return rf.FetchResults(ctx)
+    }
+    posts, err := s.FetchPosts(ctx)
+    return []SourceResult{{Source: fmt.Sprintf("%T", s), Posts: posts, Err: err}}
+}
@@ -0,0 +1,359 @@
+package scraper
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "log"
+    "net/http"
+    "net/url"
+    "strings"
+    "time"
+
+    "github.com/w1zzzle/ai-newsbot/internal/config"
+    "github.com/w1zzzle/ai-newsbot/internal/storage"
+)
+
+const redditBaseURL = "https://www.reddit.com"
+
+// JSONScraper reads Reddit's .json listing endpoints. It is the default
+// Reddit scraper; when a listing can't be fetched or decoded it falls back
+// to parsing the HTML page with RedditScraper.
+type JSONScraper struct {
+    sources  []config.Source
+    http     *fetcher
+    fallback *RedditScraper
+    seen     SeenChecker
+    // apiBase replaces the scheme and host of listing URLs when set
+    apiBase string
+}
+
+// SeenChecker reports whether a post is already stored. storage.Store
+// implements it.
+type SeenChecker interface {
+    IsPostSeen(ctx context.Context, redditID string) (bool, error)
+}
+
+// redditListing mirrors the subset of Reddit's listing JSON we use
+type redditListing struct {
+    Kind string `json:"kind"`
+    Data struct {
+        After    string        `json:"after"`
+        Children []redditChild `json:"children"`
+    } `json:"data"`
+}
+
+type redditChild struct {
+    Kind string         `json:"kind"`
+    Data redditPostData `json:"data"`
+}
+
+type redditPostData struct {
+    ID          string  `json:"id"`
+    Title       string  `json:"title"`
+    Selftext    string  `json:"selftext"`
+    Score       int     `json:"score"`
+    NumComments int     `json:"num_comments"`
+    Permalink   string  `json:"permalink"`
+    URL         string  `json:"url"`
+    Author      string  `json:"author"`
+    Subreddit   string  `json:"subreddit"`
+    CreatedUTC  float64 `json:"created_utc"`
+    IsSelf      bool    `json:"is_self"`
+    IsVideo     bool    `json:"is_video"`
+    PostHint    string  `json:"post_hint"`
+    IsGallery   bool    `json:"is_gallery"`
+    Over18      bool    `json:"over_18"`
+    Spoiler     bool    `json:"spoiler"`
+    Stickied    bool    `json:"stickied"`
+    Flair       string  `json:"link_flair_text"`
+    RemovedBy   string  `json:"removed_by_category"`
+    GalleryData *struct {
+        Items []struct {
+            MediaID string `json:"media_id"`
+        } `json:"items"`
+    } `json:"gallery_data"`
+    MediaMetadata map[string]redditMediaMetadata `json:"media_metadata"`
+    Media         *redditMediaEmbed              `json:"media"`
+    SecureMedia   *redditMediaEmbed              `json:"secure_media"`
+    Preview       *redditPreview                 `json:"preview"`
+    // CrosspostParent is the fullname (t3_<id>) of the crossposted post,
+    // which CrosspostParentList holds
+    CrosspostParent     string           `json:"crosspost_parent"`
+    CrosspostParentList []redditPostData `json:"crosspost_parent_list"`
+}
+
+func NewJSON(urls []string, upvoteThreshold int, opts ...Option) *JSONScraper {
+    return NewJSONFromSources(sourcesFromURLs(urls, upvoteThreshold), opts...)
+}
+
+// WithSeenChecker lets pagination stop at the first page made up entirely
+// of posts that are already stored
+func (s *JSONScraper) WithSeenChecker(seen SeenChecker) *JSONScraper {
+    s.seen = seen
+    return s
+}
+
+// NewJSONFromSources creates a JSON listing scraper with per-source settings.
+// The HTML fallback only reads the first page of a listing.
+func NewJSONFromSources(sources []config.Source, opts ...Option) *JSONScraper {
+    // The HTML fallback shares the HTTP layer so both respect the same
+    // per-host rate limits
+    shared := newFetcher(opts...)
+    return &JSONScraper{
+        sources:  sources,
+        http:     shared,
+        fallback: &RedditScraper{sources: sources, http: shared},
+    }
+}
+
+func (s *JSONScraper) FetchPosts(ctx context.Context) ([]storage.Post, error) {
+    return collectPosts(s.FetchResults(ctx))
+}
+
+func (s *JSONScraper) FetchResults(ctx context.Context) []SourceResult {
+    return s.http.fetchConcurrently(ctx, s.sources, func(ctx context.Context, source config.Source) SourceResult {
+        posts, err := s.fetchListing(ctx, source)
+        if err != nil && !errors.Is(err, errNotModified) && s.fallback != nil {
+            log.Printf("JSON listing for %s failed, falling back to HTML: %v", source.URL, err)
+            extraction, err := s.fallback.fetchFromURL(ctx, source.URL)
+            return extractionResult(source, extraction, err)
+        }
+        return sourceResult(source, posts, err)
+    })
+}
+
+// fetchListing reads up to source.PageDepth pages of a listing by following
+// its after cursor. It stops early at the end of the listing or once a page
+// holds nothing but posts we have already seen.
+func (s *JSONScraper) fetchListing(ctx context.Context, source config.Source) ([]storage.Post, error) {
+    listingURL, err := jsonListingURL(source.URL)
+    if err != nil {
+        return nil, err
+    }
+    if s.apiBase != "" {
+        if listingURL, err = rebaseURL(listingURL, s.apiBase); err != nil {
+            return nil, err
+        }
+    }
+
+    var allPosts []storage.Post
+    after := ""
+    for page := 0; page < max(source.PageDepth, 1); page++ {
+        posts, next, err := s.fetchPage(ctx, withAfter(listingURL, after))
+        if err != nil {
+            if page == 0 {
+                return nil, err
+            }
+            // Keep the pages we already have
+            log.Printf("Stopping %s at page %d: %v", source.URL, page+1, err)
+            break
+        }
+        allPosts = append(allPosts, posts...)
+
+        if next == "" || s.allSeen(ctx, posts) {
+            break
+        }
+        after = next
+    }
+
+    return allPosts, nil
+}
+
+// allSeen reports whether every post on a page is already stored. Without
+// a seen checker pagination never stops early.
+func (s *JSONScraper) allSeen(ctx context.Context, posts []storage.Post) bool {
+    if s.seen == nil {
+        return false
+    }
+
+    for _, post := range posts {
+        seen, err := s.seen.IsPostSeen(ctx, post.RedditID)
+        if err != nil {
+            log.Printf("Error checking if post %s is seen: %v", post.RedditID, err)
+            return false
+        }
+        if !seen {
+            return false
+        }
+    }
+
+    return true
+}
+
+// FetchPost fetches a single Reddit post by its ID
+func (s *JSONScraper) FetchPost(ctx context.Context, id string) (storage.Post, error) {
+    postURL := redditBaseURL + "/by_id/t3_" + url.PathEscape(id) + ".json"
+    if s.apiBase != "" {
+        var err error
+        if postURL, err = rebaseURL(postURL, s.apiBase); err != nil {
+            return storage.Post{}, err
+        }
+    }
+
+    posts, _, err := s.fetchPage(ctx, postURL)
+    if err != nil {
+        return storage.Post{}, err
+    }
+    if len(posts) == 0 {
+        return storage.Post{}, fmt.Errorf("post %s not found", id)
+    }
+    return posts[0], nil
+}
+
+// fetchPage fetches one listing page and returns its posts and the cursor
+// of the next page
+func (s *JSONScraper) fetchPage(ctx context.Context, listingURL string) ([]storage.Post, string, error) {
+    resp, err := s.http.get(ctx, listingURL, http.Header{"Accept": {"application/json"}})
+    if err != nil {
+        return nil, "", err
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode != http.StatusOK {
+        return nil, "", fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
+    }
+
+    var listing redditListing
+    if err := json.NewDecoder(resp.Body).Decode(&listing); err != nil {
+        return nil, "", fmt.Errorf("failed to decode listing: %w", err)
+    }
+    if listing.Kind != "Listing" {
+        return nil, "", fmt.Errorf("unexpected listing kind %q", listing.Kind)
+    }
+
+    var posts []storage.Post
+    for _, child := range listing.Data.Children {
+        // t3 is Reddit's "link" kind, i.e. a submission
+        if child.Kind != "t3" {
+            continue
+        }
+        post := child.Data.toPost()
+        if post != nil {
+            posts = append(posts, *post)
+        }
+    }
+
+    s.http.remember(ctx, resp)
+    return posts, listing.Data.After, nil
+}
+
+// withAfter adds Reddit's pagination cursor to a listing URL
+func withAfter(listingURL, after string) string {
+    if after == "" {
+        return listingURL
+    }
+
+    u, err := url.Parse(listingURL)
+    if err != nil {
+        return listingURL
+    }
+    q := u.Query()
+    q.Set("after", after)
+    u.RawQuery = q.Encode()
+
+    return u.String()
+}
+
+// rebaseURL moves a URL onto another scheme, host and path prefix, e.g.
+// from www.reddit.com to oauth.reddit.com
+func rebaseURL(rawURL, base string) (string, error) {
+    u, err := url.Parse(rawURL)
+    if err != nil {
+        return "", err
+    }
+    b, err := url.Parse(base)
+    if err != nil {
+        return "", err
+    }
+
+    u.Scheme = b.Scheme
+    u.Host = b.Host
+    u.Path = strings.TrimSuffix(b.Path, "/") + u.Path
+
+    return u.String(), nil
+}
+
+// jsonListingURL turns a subreddit page URL such as
+// https://www.reddit.com/r/OpenAI/top/?t=day into its listing endpoint
+// https://www.reddit.com/r/OpenAI/top.json?t=day
+func jsonListingURL(pageURL string) (string, error) {
+    u, err := url.Parse(pageURL)
+    if err != nil {
+        return "", err
+    }
+    if u.Scheme == "" || u.Host == "" {
+        return "", fmt.Errorf("invalid listing URL %q", pageURL)
+    }
+
+    path := strings.TrimSuffix(u.Path, "/")
+    if !strings.HasSuffix(path, ".json") {
+        if path == "" {
+            path = "/"
+        }
+        u.Path = path + ".json"
+    }
+
+    return u.String(), nil
+}
+
+func (d redditPostData) toPost() *storage.Post {
+    if d.ID == "" || strings.TrimSpace(d.Title) == "" {
+        return nil
+    }
+
+    permalink := d.Permalink
+    if strings.HasPrefix(permalink, "/") {
+        permalink = redditBaseURL + permalink
+    }
+
+    link := d.linkURL()
+    if link == "" && len(d.CrosspostParentList) > 0 {
+        link = d.CrosspostParentList[0].linkURL()
+    }
+
+    media := d.resolveMedia()
+    return &storage.Post{
+        RedditID:        d.ID,
+        Title:           strings.TrimSpace(d.Title),
+        Body:            strings.TrimSpace(d.Selftext),
+        Score:           d.Score,
+        NumComments:     d.NumComments,
+        Permalink:       permalink,
+        URL:             link,
+        Author:          d.Author,
+        Subreddit:       d.Subreddit,
+        Flair:           strings.TrimSpace(d.Flair),
+        NSFW:            d.Over18,
+        Spoiler:         d.Spoiler,
+        Stickied:        d.Stickied,
+        IsSelf:          d.IsSelf,
+        RemovedBy:       d.RemovedBy,
+        CrosspostParent: strings.TrimPrefix(d.CrosspostParent, "t3_"),
+        MediaURLs:       mediaURLs(media),
+        Media:           media,
+        CreatedAt:       time.Unix(int64(d.CreatedUTC), 0).UTC(),
+    }
+}
+
+// linkURL returns the outbound URL of a link post. Self posts and media
+// hosted by Reddit have none.
+func (d redditPostData) linkURL() string {
+    if d.IsSelf || d.IsVideo || d.PostHint == "image" || !strings.HasPrefix(d.URL, "http") {
+        return ""
+    }
+
+    u, err := url.Parse(d.URL)
+    if err != nil {
+        return ""
+    }
+    host := strings.ToLower(u.Hostname())
+    for _, domain := range []string{"reddit.com", "redd.it"} {
+        if host == domain || strings.HasSuffix(host, "."+domain) {
+            return ""
+        }
+    }
+
+    return d.URL
+}
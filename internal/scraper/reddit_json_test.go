@@ -0,0 +1,280 @@
+package scraper
+
+import (
+    "context"
+    "net/http"
+    "net/http/httptest"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "github.com/w1zzzle/ai-newsbot/internal/config"
+    "github.com/w1zzzle/ai-newsbot/internal/storage"
+)
+
+// serveFixture returns a handler that serves a recorded listing from testdata
+func serveFixture(t *testing.T, name string) http.HandlerFunc {
+    t.Helper()
+    data, err := os.ReadFile(filepath.Join("testdata", name))
+    require.NoError(t, err)
+
+    return func(w http.ResponseWriter, r *http.Request) {
+        w.Header().Set("Content-Type", "application/json")
+        w.WriteHeader(http.StatusOK)
+        w.Write(data)
+    }
+}
+
+func TestJSONScraper_FetchPosts(t *testing.T) {
+    var requestedPath string
+    fixture := serveFixture(t, "listing_top.json")
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        requestedPath = r.URL.Path
+        fixture(w, r)
+    }))
+    defer server.Close()
+
+    scraper := NewJSON([]string{server.URL + "/r/MachineLearning/top/"}, 50)
+    posts, err := scraper.FetchPosts(context.Background())
+    require.NoError(t, err)
+    require.Len(t, posts, 3)
+
+    assert.Equal(t, "/r/MachineLearning/top.json", requestedPath)
+
+    // Self post
+    assert.Equal(t, "1b9xq2a", posts[0].RedditID)
+    assert.Equal(t, "[R] Open 7B model matches 70B on reasoning benchmarks", posts[0].Title)
+    assert.True(t, strings.HasPrefix(posts[0].Body, "We release the weights"))
+    assert.Equal(t, 1843, posts[0].Score)
+    assert.Equal(t, 212, posts[0].NumComments)
+    assert.Equal(t, "https://www.reddit.com/r/MachineLearning/comments/1b9xq2a/r_open_7b_model_matches_70b_on_reasoning/", posts[0].Permalink)
+    assert.Equal(t, "research_throwaway", posts[0].Author)
+    assert.Equal(t, "MachineLearning", posts[0].Subreddit)
+    assert.Empty(t, posts[0].MediaURLs)
+    assert.Equal(t, time.Unix(1710162000, 0).UTC(), posts[0].CreatedAt)
+
+    // Image post
+    assert.Equal(t, "1b9yz7c", posts[1].RedditID)
+    assert.Equal(t, []string{"https://i.redd.it/q7z4m2benchmark.png"}, posts[1].MediaURLs)
+
+    // Hosted video
+    assert.Equal(t, "1ba0v1d", posts[2].RedditID)
+    assert.Equal(t, []string{"https://v.redd.it/k3n8x1demo/DASH_720.mp4?source=fallback"}, posts[2].MediaURLs)
+}
+
+func TestJSONScraper_FetchPosts_BelowThreshold(t *testing.T) {
+    server := httptest.NewServer(serveFixture(t, "listing_top.json"))
+    defer server.Close()
+
+    scraper := NewJSON([]string{server.URL + "/r/MachineLearning/top/"}, 100)
+    posts, err := scraper.FetchPosts(context.Background())
+    require.NoError(t, err)
+    require.Len(t, posts, 2)
+    assert.Equal(t, "1b9xq2a", posts[0].RedditID)
+    assert.Equal(t, "1b9yz7c", posts[1].RedditID)
+}
+
+func TestJSONScraper_FetchPosts_EmptyListing(t *testing.T) {
+    server := httptest.NewServer(serveFixture(t, "listing_empty.json"))
+    defer server.Close()
+
+    scraper := NewJSON([]string{server.URL + "/r/OpenAI/top/"}, 100)
+    posts, err := scraper.FetchPosts(context.Background())
+    require.NoError(t, err)
+    assert.Empty(t, posts)
+}
+
+func TestJSONScraper_FetchPosts_FallsBackToHTML(t *testing.T) {
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if strings.HasSuffix(r.URL.Path, ".json") {
+            w.WriteHeader(http.StatusForbidden)
+            return
+        }
+        w.WriteHeader(http.StatusOK)
+        w.Write([]byte(`<html><body>
+            <div data-testid="post-container" data-post-id="html123" data-score="2.4k">
+                <h3 data-testid="post-title">From the HTML page</h3>
+            </div>
+        </body></html>`))
+    }))
+    defer server.Close()
+
+    scraper := NewJSON([]string{server.URL + "/r/OpenAI/top/"}, 100)
+    posts, err := scraper.FetchPosts(context.Background())
+    require.NoError(t, err)
+    require.Len(t, posts, 1)
+    assert.Equal(t, "html123", posts[0].RedditID)
+}
+
+func TestJSONScraper_FetchPosts_HTTPError(t *testing.T) {
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.WriteHeader(http.StatusInternalServerError)
+    }))
+    defer server.Close()
+
+    scraper := NewJSON([]string{server.URL}, 100)
+    posts, err := scraper.FetchPosts(context.Background())
+    assert.Error(t, err)
+    assert.Nil(t, posts)
+}
+
+func TestJSONListingURL(t *testing.T) {
+    testCases := []struct {
+        input    string
+        expected string
+    }{
+        {"https://www.reddit.com/r/OpenAI/top/", "https://www.reddit.com/r/OpenAI/top.json"},
+        {"https://www.reddit.com/r/OpenAI/top/?t=day", "https://www.reddit.com/r/OpenAI/top.json?t=day"},
+        {"https://www.reddit.com/r/OpenAI/new.json", "https://www.reddit.com/r/OpenAI/new.json"},
+        {"http://127.0.0.1:8080", "http://127.0.0.1:8080/.json"},
+    }
+
+    for _, tc := range testCases {
+        result, err := jsonListingURL(tc.input)
+        require.NoError(t, err)
+        assert.Equal(t, tc.expected, result)
+    }
+
+    _, err := jsonListingURL("invalid-url")
+    assert.Error(t, err)
+}
+
+// pagedListing serves three listing pages chained by their after cursors
+func pagedListing(t *testing.T, requested *[]string) *httptest.Server {
+    t.Helper()
+    pages := map[string]string{
+        "":       `{"kind": "Listing", "data": {"after": "t3_p1b", "children": [{"kind": "t3", "data": {"id": "p1a", "title": "Page one A", "score": 500}}, {"kind": "t3", "data": {"id": "p1b", "title": "Page one B", "score": 400}}]}}`,
+        "t3_p1b": `{"kind": "Listing", "data": {"after": "t3_p2a", "children": [{"kind": "t3", "data": {"id": "p2a", "title": "Page two A", "score": 300}}]}}`,
+        "t3_p2a": `{"kind": "Listing", "data": {"after": null, "children": [{"kind": "t3", "data": {"id": "p3a", "title": "Page three A", "score": 200}}]}}`,
+    }
+
+    return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        after := r.URL.Query().Get("after")
+        *requested = append(*requested, after)
+        page, ok := pages[after]
+        if !ok {
+            w.WriteHeader(http.StatusNotFound)
+            return
+        }
+        w.WriteHeader(http.StatusOK)
+        w.Write([]byte(page))
+    }))
+}
+
+type seenSet map[string]bool
+
+func (s seenSet) IsPostSeen(ctx context.Context, redditID string) (bool, error) {
+    return s[redditID], nil
+}
+
+func postIDs(posts []storage.Post) []string {
+    var ids []string
+    for _, post := range posts {
+        ids = append(ids, post.RedditID)
+    }
+    return ids
+}
+
+func TestJSONScraper_FetchPosts_PageDepth(t *testing.T) {
+    var requested []string
+    server := pagedListing(t, &requested)
+    defer server.Close()
+
+    scraper := NewJSONFromSources([]config.Source{
+        {URL: server.URL + "/r/LocalLLaMA/top/", UpvoteThreshold: 100, PageDepth: 2},
+    })
+
+    posts, err := scraper.FetchPosts(context.Background())
+    require.NoError(t, err)
+    assert.Equal(t, []string{"p1a", "p1b", "p2a"}, postIDs(posts))
+    assert.Equal(t, []string{"", "t3_p1b"}, requested)
+}
+
+func TestJSONScraper_FetchPosts_StopsAtEndOfListing(t *testing.T) {
+    var requested []string
+    server := pagedListing(t, &requested)
+    defer server.Close()
+
+    scraper := NewJSONFromSources([]config.Source{
+        {URL: server.URL + "/r/LocalLLaMA/top/", UpvoteThreshold: 100, PageDepth: 10},
+    })
+
+    posts, err := scraper.FetchPosts(context.Background())
+    require.NoError(t, err)
+    assert.Equal(t, []string{"p1a", "p1b", "p2a", "p3a"}, postIDs(posts))
+    assert.Len(t, requested, 3)
+}
+
+func TestJSONScraper_FetchPosts_StopsAtSeenPage(t *testing.T) {
+    var requested []string
+    server := pagedListing(t, &requested)
+    defer server.Close()
+
+    scraper := NewJSONFromSources([]config.Source{
+        {URL: server.URL + "/r/LocalLLaMA/top/", UpvoteThreshold: 100, PageDepth: 3},
+    }).WithSeenChecker(seenSet{"p1a": true, "p1b": true})
+
+    posts, err := scraper.FetchPosts(context.Background())
+    require.NoError(t, err)
+    assert.Equal(t, []string{"p1a", "p1b"}, postIDs(posts))
+    assert.Equal(t, []string{""}, requested)
+}
+
+func TestJSONScraper_FetchPosts_KeepsPagesBeforeError(t *testing.T) {
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if r.URL.Query().Get("after") != "" {
+            w.WriteHeader(http.StatusInternalServerError)
+            return
+        }
+        w.WriteHeader(http.StatusOK)
+        w.Write([]byte(`{"kind": "Listing", "data": {"after": "t3_p1a", "children": [{"kind": "t3", "data": {"id": "p1a", "title": "Page one A", "score": 500}}]}}`))
+    }))
+    defer server.Close()
+
+    scraper := NewJSONFromSources([]config.Source{
+        {URL: server.URL + "/r/LocalLLaMA/top/", UpvoteThreshold: 100, PageDepth: 3},
+    })
+
+    posts, err := scraper.FetchPosts(context.Background())
+    require.NoError(t, err)
+    assert.Equal(t, []string{"p1a"}, postIDs(posts))
+}
+
+func TestJSONScraper_FetchPost(t *testing.T) {
+    var requestedPath string
+    fixture := serveFixture(t, "listing_top.json")
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        requestedPath = r.URL.Path
+        fixture(w, r)
+    }))
+    defer server.Close()
+
+    scraper := NewJSON(nil, 0)
+    scraper.apiBase = server.URL
+
+    post, err := scraper.FetchPost(context.Background(), "1b9xq2a")
+    require.NoError(t, err)
+    assert.Equal(t, "/by_id/t3_1b9xq2a.json", requestedPath)
+    assert.Equal(t, "1b9xq2a", post.RedditID)
+}
+
+func TestRedditPostData_ToPost_Crosspost(t *testing.T) {
+    data := redditPostData{
+        ID:              "xp1",
+        Title:           "OpenAI announces GPT-5",
+        URL:             "/r/OpenAI/comments/oa1/openai_announces_gpt5/",
+        CrosspostParent: "t3_oa1",
+        CrosspostParentList: []redditPostData{
+            {ID: "oa1", URL: "https://openai.com/index/gpt-5/"},
+        },
+    }
+
+    post := data.toPost()
+    require.NotNil(t, post)
+    assert.Equal(t, "oa1", post.CrosspostParent)
+    assert.Equal(t, "https://openai.com/index/gpt-5/", post.URL)
+}
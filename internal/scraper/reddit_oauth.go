@@ -0,0 +1,268 @@
+package scraper
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "io"
+    "log"
+    "net/http"
+    "net/url"
+    "strconv"
+    "strings"
+    "sync"
+    "time"
+
+    "github.com/w1zzzle/ai-newsbot/internal/config"
+)
+
+const (
+    redditTokenURL  = "https://www.reddit.com/api/v1/access_token"
+    redditAPIURL    = "https://oauth.reddit.com"
+    tokenRefreshGap = time.Minute
+)
+
+// OAuthConfig holds Reddit API credentials. With Username and Password set
+// the script-app password grant is used, otherwise app-only client
+// credentials.
+type OAuthConfig struct {
+    ClientID     string
+    ClientSecret string
+    Username     string
+    Password     string
+    // UserAgent should follow Reddit's "<platform>:<app ID>:<version> (by
+    // /u/<username>)" format; requests with generic agents get throttled
+    UserAgent  string
+    TokenURL   string
+    APIBaseURL string
+}
+
+// OAuthScraper reads listings through Reddit's authenticated API, which
+// has far more generous rate limits than anonymous scraping
+type OAuthScraper struct {
+    *JSONScraper
+    auth *oauthTransport
+}
+
+// NewOAuth creates a scraper that authenticates against the Reddit API
+func NewOAuth(sources []config.Source, oauth OAuthConfig, opts ...Option) *OAuthScraper {
+    if oauth.TokenURL == "" {
+        oauth.TokenURL = redditTokenURL
+    }
+    if oauth.APIBaseURL == "" {
+        oauth.APIBaseURL = redditAPIURL
+    }
+    if oauth.UserAgent == "" {
+        oauth.UserAgent = defaultOAuthUserAgent(oauth.ClientID, oauth.Username)
+    }
+
+    s := NewJSONFromSources(sources, opts...)
+    auth := newOAuthTransport(oauth, s.http.client.Transport)
+    s.http.client.Transport = auth
+    s.apiBase = oauth.APIBaseURL
+    // HTML pages aren't served by the API host
+    s.fallback = nil
+
+    return &OAuthScraper{JSONScraper: s, auth: auth}
+}
+
+// RateLimit returns the remaining request budget and when it resets, as
+// last reported by the API. ok is false until a response has been seen.
+func (s *OAuthScraper) RateLimit() (remaining float64, reset time.Time, ok bool) {
+    return s.auth.rateLimit()
+}
+
+func defaultOAuthUserAgent(clientID, username string) string {
+    if username == "" {
+        username = "unknown"
+    }
+    return fmt.Sprintf("server:ai-newsbot:%s:v1.0 (by /u/%s)", clientID, username)
+}
+
+// oauthTransport adds a cached bearer token to every request, refreshing
+// it shortly before it expires, and waits out an exhausted rate limit
+type oauthTransport struct {
+    cfg         OAuthConfig
+    base        http.RoundTripper
+    tokenClient *http.Client
+    now         func() time.Time
+
+    mu        sync.Mutex
+    token     string
+    expiry    time.Time
+    remaining float64
+    reset     time.Time
+    known     bool
+}
+
+type tokenResponse struct {
+    AccessToken string `json:"access_token"`
+    TokenType   string `json:"token_type"`
+    ExpiresIn   int    `json:"expires_in"`
+    Error       string `json:"error"`
+}
+
+func newOAuthTransport(cfg OAuthConfig, base http.RoundTripper) *oauthTransport {
+    if base == nil {
+        base = http.DefaultTransport
+    }
+    return &oauthTransport{
+        cfg:  cfg,
+        base: base,
+        tokenClient: &http.Client{
+            Timeout:   30 * time.Second,
+            Transport: base,
+        },
+        now: time.Now,
+    }
+}
+
+func (t *oauthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+    ctx := req.Context()
+
+    token, err := t.accessToken(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    if err := sleep(ctx, t.rateLimitWait()); err != nil {
+        return nil, err
+    }
+
+    // RoundTrippers must not modify the caller's request
+    req = req.Clone(ctx)
+    req.Header.Set("Authorization", "bearer "+token)
+    req.Header.Set("User-Agent", t.cfg.UserAgent)
+
+    resp, err := t.base.RoundTrip(req)
+    if err != nil {
+        return nil, err
+    }
+
+    t.trackRateLimit(resp.Header)
+    if resp.StatusCode == http.StatusUnauthorized {
+        // The token was revoked or expired early; fetch a new one next time
+        t.invalidate(token)
+    }
+
+    return resp, nil
+}
+
+// accessToken returns the cached token, requesting a new one when it is
+// missing or about to expire
+func (t *oauthTransport) accessToken(ctx context.Context) (string, error) {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+
+    if t.token != "" && t.now().Add(tokenRefreshGap).Before(t.expiry) {
+        return t.token, nil
+    }
+
+    token, expiresIn, err := t.requestToken(ctx)
+    if err != nil {
+        return "", fmt.Errorf("failed to get reddit access token: %w", err)
+    }
+
+    t.token = token
+    t.expiry = t.now().Add(expiresIn)
+    return t.token, nil
+}
+
+func (t *oauthTransport) requestToken(ctx context.Context) (string, time.Duration, error) {
+    form := url.Values{}
+    if t.cfg.Username != "" && t.cfg.Password != "" {
+        form.Set("grant_type", "password")
+        form.Set("username", t.cfg.Username)
+        form.Set("password", t.cfg.Password)
+    } else {
+        form.Set("grant_type", "client_credentials")
+    }
+
+    req, err := http.NewRequestWithContext(ctx, "POST", t.cfg.TokenURL, strings.NewReader(form.Encode()))
+    if err != nil {
+        return "", 0, err
+    }
+    req.SetBasicAuth(t.cfg.ClientID, t.cfg.ClientSecret)
+    req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+    req.Header.Set("User-Agent", t.cfg.UserAgent)
+
+    resp, err := t.tokenClient.Do(req)
+    if err != nil {
+        return "", 0, err
+    }
+    defer resp.Body.Close()
+
+    body, err := io.ReadAll(resp.Body)
+    if err != nil {
+        return "", 0, err
+    }
+    if resp.StatusCode != http.StatusOK {
+        return "", 0, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body))
+    }
+
+    var token tokenResponse
+    if err := json.Unmarshal(body, &token); err != nil {
+        return "", 0, fmt.Errorf("failed to decode token response: %w", err)
+    }
+    if token.Error != "" {
+        return "", 0, fmt.Errorf("token endpoint error: %s", token.Error)
+    }
+    if token.AccessToken == "" {
+        return "", 0, fmt.Errorf("token endpoint returned no access token")
+    }
+
+    return token.AccessToken, time.Duration(token.ExpiresIn) * time.Second, nil
+}
+
+func (t *oauthTransport) invalidate(token string) {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+
+    if t.token == token {
+        t.token = ""
+    }
+}
+
+// trackRateLimit records the X-Ratelimit-Remaining/X-Ratelimit-Reset
+// headers Reddit sends with every API response
+func (t *oauthTransport) trackRateLimit(header http.Header) {
+    remaining, err := strconv.ParseFloat(header.Get("X-Ratelimit-Remaining"), 64)
+    if err != nil {
+        return
+    }
+    resetSeconds, err := strconv.Atoi(header.Get("X-Ratelimit-Reset"))
+    if err != nil {
+        return
+    }
+
+    t.mu.Lock()
+    defer t.mu.Unlock()
+
+    t.remaining = remaining
+    t.reset = t.now().Add(time.Duration(resetSeconds) * time.Second)
+    t.known = true
+}
+
+// rateLimitWait returns how long to hold off when the request budget for
+// the current window is used up
+func (t *oauthTransport) rateLimitWait() time.Duration {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+
+    if !t.known || t.remaining >= 1 {
+        return 0
+    }
+
+    wait := t.reset.Sub(t.now())
+    if wait > 0 {
+        log.Printf("Reddit API rate limit exhausted, waiting %s", wait)
+    }
+    return wait
+}
+
+func (t *oauthTransport) rateLimit() (float64, time.Time, bool) {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+
+    return t.remaining, t.reset, t.known
+}
@@ -0,0 +1,169 @@
+package scraper
+
+import (
+    "context"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "os"
+    "path/filepath"
+    "sync/atomic"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "github.com/w1zzzle/ai-newsbot/internal/config"
+)
+
+// fakeRedditAPI serves a token endpoint and an API host that only accepts
+// the most recently issued token
+type fakeRedditAPI struct {
+    *httptest.Server
+    tokensIssued int32
+    expiresIn    int
+    lastGrant    string
+    lastAgent    string
+}
+
+func newFakeRedditAPI(t *testing.T, expiresIn int) *fakeRedditAPI {
+    t.Helper()
+    listing, err := os.ReadFile(filepath.Join("testdata", "listing_top.json"))
+    require.NoError(t, err)
+
+    api := &fakeRedditAPI{expiresIn: expiresIn}
+    mux := http.NewServeMux()
+
+    mux.HandleFunc("/api/v1/access_token", func(w http.ResponseWriter, r *http.Request) {
+        user, pass, ok := r.BasicAuth()
+        if !ok || user != "client-id" || pass != "client-secret" {
+            w.WriteHeader(http.StatusUnauthorized)
+            w.Write([]byte(`{"message": "Unauthorized", "error": 401}`))
+            return
+        }
+        require.NoError(t, r.ParseForm())
+        api.lastGrant = r.PostForm.Get("grant_type")
+
+        n := atomic.AddInt32(&api.tokensIssued, 1)
+        w.Header().Set("Content-Type", "application/json")
+        fmt.Fprintf(w, `{"access_token": "token-%d", "token_type": "bearer", "expires_in": %d, "scope": "*"}`, n, api.expiresIn)
+    })
+
+    mux.HandleFunc("/r/MachineLearning/top.json", func(w http.ResponseWriter, r *http.Request) {
+        api.lastAgent = r.Header.Get("User-Agent")
+        expected := fmt.Sprintf("bearer token-%d", atomic.LoadInt32(&api.tokensIssued))
+        if r.Header.Get("Authorization") != expected {
+            w.WriteHeader(http.StatusUnauthorized)
+            return
+        }
+        w.Header().Set("X-Ratelimit-Remaining", "99.0")
+        w.Header().Set("X-Ratelimit-Used", "1")
+        w.Header().Set("X-Ratelimit-Reset", "420")
+        w.WriteHeader(http.StatusOK)
+        w.Write(listing)
+    })
+
+    api.Server = httptest.NewServer(mux)
+    return api
+}
+
+func newTestOAuthScraper(api *fakeRedditAPI, oauth OAuthConfig) *OAuthScraper {
+    oauth.ClientID = "client-id"
+    oauth.ClientSecret = "client-secret"
+    oauth.TokenURL = api.URL + "/api/v1/access_token"
+    oauth.APIBaseURL = api.URL
+
+    return NewOAuth([]config.Source{
+        {URL: "https://www.reddit.com/r/MachineLearning/top/", UpvoteThreshold: 100},
+    }, oauth)
+}
+
+func TestOAuthScraper_FetchPosts(t *testing.T) {
+    api := newFakeRedditAPI(t, 3600)
+    defer api.Close()
+
+    scraper := newTestOAuthScraper(api, OAuthConfig{UserAgent: "server:ai-newsbot:v1.0 (by /u/tester)"})
+
+    posts, err := scraper.FetchPosts(context.Background())
+    require.NoError(t, err)
+    assert.Len(t, posts, 2)
+    assert.Equal(t, "client_credentials", api.lastGrant)
+    assert.Equal(t, "server:ai-newsbot:v1.0 (by /u/tester)", api.lastAgent)
+
+    // The cached token is reused on the next run
+    _, err = scraper.FetchPosts(context.Background())
+    require.NoError(t, err)
+    assert.Equal(t, int32(1), atomic.LoadInt32(&api.tokensIssued))
+
+    remaining, reset, ok := scraper.RateLimit()
+    require.True(t, ok)
+    assert.Equal(t, 99.0, remaining)
+    assert.WithinDuration(t, time.Now().Add(420*time.Second), reset, 5*time.Second)
+}
+
+func TestOAuthScraper_PasswordGrant(t *testing.T) {
+    api := newFakeRedditAPI(t, 3600)
+    defer api.Close()
+
+    scraper := newTestOAuthScraper(api, OAuthConfig{Username: "newsbot", Password: "hunter2"})
+
+    _, err := scraper.FetchPosts(context.Background())
+    require.NoError(t, err)
+    assert.Equal(t, "password", api.lastGrant)
+    assert.Equal(t, "server:ai-newsbot:client-id:v1.0 (by /u/newsbot)", api.lastAgent)
+}
+
+func TestOAuthScraper_RefreshesBeforeExpiry(t *testing.T) {
+    api := newFakeRedditAPI(t, 3600)
+    defer api.Close()
+
+    scraper := newTestOAuthScraper(api, OAuthConfig{})
+    now := time.Now()
+    scraper.auth.now = func() time.Time { return now }
+
+    _, err := scraper.FetchPosts(context.Background())
+    require.NoError(t, err)
+
+    // Inside the refresh window the token is replaced before it expires
+    now = now.Add(time.Hour - 30*time.Second)
+    _, err = scraper.FetchPosts(context.Background())
+    require.NoError(t, err)
+    assert.Equal(t, int32(2), atomic.LoadInt32(&api.tokensIssued))
+}
+
+func TestOAuthScraper_InvalidCredentials(t *testing.T) {
+    api := newFakeRedditAPI(t, 3600)
+    defer api.Close()
+
+    scraper := newTestOAuthScraper(api, OAuthConfig{})
+    scraper.auth.cfg.ClientSecret = "wrong"
+
+    posts, err := scraper.FetchPosts(context.Background())
+    assert.Error(t, err)
+    assert.Contains(t, err.Error(), "access token")
+    assert.Nil(t, posts)
+}
+
+func TestOAuthTransport_RateLimitWait(t *testing.T) {
+    transport := newOAuthTransport(OAuthConfig{}, nil)
+    now := time.Date(2024, 3, 11, 12, 0, 0, 0, time.UTC)
+    transport.now = func() time.Time { return now }
+
+    assert.Zero(t, transport.rateLimitWait())
+
+    header := http.Header{}
+    header.Set("X-Ratelimit-Remaining", "0.0")
+    header.Set("X-Ratelimit-Reset", "42")
+    transport.trackRateLimit(header)
+    assert.Equal(t, 42*time.Second, transport.rateLimitWait())
+
+    header.Set("X-Ratelimit-Remaining", "12.0")
+    transport.trackRateLimit(header)
+    assert.Zero(t, transport.rateLimitWait())
+}
+
+func TestRebaseURL(t *testing.T) {
+    rebased, err := rebaseURL("https://www.reddit.com/r/OpenAI/top.json?t=day", "https://oauth.reddit.com")
+    require.NoError(t, err)
+    assert.Equal(t, "https://oauth.reddit.com/r/OpenAI/top.json?t=day", rebased)
+}
@@ -0,0 +1,161 @@
+package scraper
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "sort"
+    "sync"
+
+    "github.com/w1zzzle/ai-newsbot/internal/config"
+    "github.com/w1zzzle/ai-newsbot/internal/storage"
+)
+
+// SourceResult is what a single source produced during one run
+type SourceResult struct {
+    Source         string
+    Posts          []storage.Post
+    BelowThreshold int
+    // Filtered counts posts dropped by the source's include and exclude rules
+    Filtered int
+    // NotModified is set when the server answered 304 and nothing was parsed
+    NotModified bool
+    // Containers and Skipped count the post elements found on an HTML page
+    // and those that failed to extract. Both are zero for other sources.
+    Containers int
+    Skipped    int
+    Err        error
+
+    validators *pendingValidators
+}
+
+// CommitCache stores the ETag/Last-Modified values of the responses behind
+// the result, so the source answers 304 next run while it is unchanged.
+// The pipeline calls it once the source's posts are saved; a source with
+// posts left unsaved is downloaded in full again and yields them anew.
+func (r SourceResult) CommitCache(ctx context.Context) {
+    if r.validators != nil {
+        r.validators.commit(ctx)
+    }
+}
+
+// ResultFetcher is implemented by scrapers that can report per-source
+// results, so one failing source doesn't discard the others
+type ResultFetcher interface {
+    FetchResults(ctx context.Context) []SourceResult
+}
+
+// extractionResult builds the result of scraping an HTML page
+func extractionResult(source config.Source, extraction Extraction, err error) SourceResult {
+    result := sourceResult(source, extraction.Posts, err)
+    if err == nil {
+        result.Containers = extraction.Containers
+        result.Skipped = extraction.Skipped
+    }
+    return result
+}
+
+// sourceResult builds the result of fetching a source, applying its upvote
+// threshold and its include and exclude rules to the posts
+func sourceResult(source config.Source, posts []storage.Post, err error) SourceResult {
+    result := SourceResult{Source: source.URL}
+
+    switch {
+    case errors.Is(err, errNotModified):
+        result.NotModified = true
+    case err != nil:
+        result.Err = err
+    default:
+        result.Posts, result.BelowThreshold = applyThreshold(source, posts)
+        result.Posts, result.Filtered = applyRules(source, result.Posts)
+    }
+
+    return result
+}
+
+// collectPosts flattens per-source results. It only fails when every
+// source failed. Cache validators are not committed, as callers of
+// FetchPosts don't report which posts they saved.
+func collectPosts(results []SourceResult) ([]storage.Post, error) {
+    var posts []storage.Post
+    var errs []error
+
+    for _, result := range results {
+        if result.Err != nil {
+            errs = append(errs, fmt.Errorf("failed to fetch from %s: %w", result.Source, result.Err))
+            continue
+        }
+        posts = append(posts, result.Posts...)
+    }
+
+    if len(results) > 0 && len(errs) == len(results) {
+        return nil, errors.Join(errs...)
+    }
+
+    return posts, nil
+}
+
+// FailureTracker counts consecutive failed runs per source
+type FailureTracker struct {
+    mu     sync.Mutex
+    counts map[string]int
+}
+
+func NewFailureTracker() *FailureTracker {
+    return &FailureTracker{
+        counts: make(map[string]int),
+    }
+}
+
+// Record updates the counters from one run's results. A successful run
+// resets the source's counter.
+func (t *FailureTracker) Record(results []SourceResult) {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+
+    for _, result := range results {
+        if result.Err != nil {
+            t.counts[result.Source]++
+        } else {
+            delete(t.counts, result.Source)
+        }
+    }
+}
+
+// Failures returns how many runs in a row the source has failed
+func (t *FailureTracker) Failures(source string) int {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+
+    return t.counts[source]
+}
+
+// Failing returns the sources that have failed at least n runs in a row,
+// sorted by name
+func (t *FailureTracker) Failing(n int) []string {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+
+    var sources []string
+    for source, count := range t.counts {
+        if count >= n {
+            sources = append(sources, source)
+        }
+    }
+    sort.Strings(sources)
+
+    return sources
+}
+
+// Snapshot returns a copy of the current failure counts
+func (t *FailureTracker) Snapshot() map[string]int {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+
+    snapshot := make(map[string]int, len(t.counts))
+    for source, count := range t.counts {
+        snapshot[source] = count
+    }
+
+    return snapshot
+}
@@ -0,0 +1,91 @@
+package scraper
+
+import (
+    "context"
+    "errors"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "github.com/w1zzzle/ai-newsbot/internal/storage"
+)
+
+func TestJSONScraper_FetchResults_PartialFailure(t *testing.T) {
+    fixture := serveFixture(t, "listing_top.json")
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if strings.HasPrefix(r.URL.Path, "/r/OpenAI") {
+            w.Header().Set("Retry-After", "0")
+            w.WriteHeader(http.StatusTooManyRequests)
+            return
+        }
+        fixture(w, r)
+    }))
+    defer server.Close()
+
+    scraper := NewJSON([]string{
+        server.URL + "/r/OpenAI/top/",
+        server.URL + "/r/MachineLearning/top/",
+    }, 100, WithMaxRetries(0))
+
+    results := scraper.FetchResults(context.Background())
+    require.Len(t, results, 2)
+
+    assert.Error(t, results[0].Err)
+    assert.Empty(t, results[0].Posts)
+
+    require.NoError(t, results[1].Err)
+    assert.Len(t, results[1].Posts, 2)
+    assert.Equal(t, 1, results[1].BelowThreshold)
+
+    // FetchPosts keeps whatever succeeded
+    posts, err := scraper.FetchPosts(context.Background())
+    require.NoError(t, err)
+    assert.Len(t, posts, 2)
+}
+
+func TestCollectPosts(t *testing.T) {
+    posts, err := collectPosts([]SourceResult{
+        {Source: "a", Err: errors.New("HTTP 429")},
+        {Source: "b", Posts: []storage.Post{{RedditID: "b1"}}},
+    })
+    require.NoError(t, err)
+    assert.Len(t, posts, 1)
+
+    posts, err = collectPosts([]SourceResult{
+        {Source: "a", Err: errors.New("HTTP 429")},
+        {Source: "b", Err: errors.New("HTTP 503")},
+    })
+    assert.Error(t, err)
+    assert.Contains(t, err.Error(), "HTTP 429")
+    assert.Contains(t, err.Error(), "HTTP 503")
+    assert.Nil(t, posts)
+
+    posts, err = collectPosts(nil)
+    require.NoError(t, err)
+    assert.Empty(t, posts)
+}
+
+func TestFailureTracker(t *testing.T) {
+    tracker := NewFailureTracker()
+    failing := []SourceResult{
+        {Source: "r/OpenAI", Err: errors.New("HTTP 429")},
+        {Source: "r/MachineLearning"},
+    }
+
+    tracker.Record(failing)
+    tracker.Record(failing)
+    tracker.Record(failing)
+
+    assert.Equal(t, 3, tracker.Failures("r/OpenAI"))
+    assert.Equal(t, 0, tracker.Failures("r/MachineLearning"))
+    assert.Equal(t, []string{"r/OpenAI"}, tracker.Failing(3))
+    assert.Empty(t, tracker.Failing(4))
+
+    // A successful run resets the counter
+    tracker.Record([]SourceResult{{Source: "r/OpenAI"}})
+    assert.Equal(t, 0, tracker.Failures("r/OpenAI"))
+    assert.Empty(t, tracker.Snapshot())
+}
@@ -3,13 +3,16 @@ package scraper
 import (
     "context"
     "fmt"
+    "math"
     "net/http"
+    "path"
     "regexp"
     "strconv"
     "strings"
     "time"
 
     "github.com/PuerkitoBio/goquery"
+    "github.com/w1zzzle/ai-newsbot/internal/config"
     "github.com/w1zzzle/ai-newsbot/internal/storage"
 )
 
@@ -18,70 +21,65 @@ type Scraper interface {
 }
 
 type RedditScraper struct {
-    urls            []string
-    upvoteThreshold int
-    client          *http.Client
+    sources []config.Source
+    http    *fetcher
 }
 
-func New(urls []string, upvoteThreshold int) *RedditScraper {
+func New(urls []string, upvoteThreshold int, opts ...Option) *RedditScraper {
+    return NewFromSources(sourcesFromURLs(urls, upvoteThreshold), opts...)
+}
+
+// NewFromSources creates an HTML scraper with per-source settings
+func NewFromSources(sources []config.Source, opts ...Option) *RedditScraper {
     return &RedditScraper{
-        urls:            urls,
-        upvoteThreshold: upvoteThreshold,
-        client: &http.Client{
-            Timeout: 30 * time.Second,
-        },
+        sources: sources,
+        http:    newFetcher(opts...),
     }
 }
 
 func (s *RedditScraper) FetchPosts(ctx context.Context) ([]storage.Post, error) {
-    var allPosts []storage.Post
-
-    for _, url := range s.urls {
-        posts, err := s.fetchFromURL(ctx, url)
-        if err != nil {
-            return nil, fmt.Errorf("failed to fetch from %s: %w", url, err)
-        }
-        allPosts = append(allPosts, posts...)
-    }
-
-    return allPosts, nil
+    return collectPosts(s.FetchResults(ctx))
 }
 
-func (s *RedditScraper) fetchFromURL(ctx context.Context, url string) ([]storage.Post, error) {
-    req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-    if err != nil {
-        return nil, err
-    }
-
-    // Set User-Agent to avoid being blocked
-    req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; AI-NewsBot/1.0)")
+func (s *RedditScraper) FetchResults(ctx context.Context) []SourceResult {
+    return s.http.fetchConcurrently(ctx, s.sources, func(ctx context.Context, source config.Source) SourceResult {
+        extraction, err := s.fetchFromURL(ctx, source.URL)
+        return extractionResult(source, extraction, err)
+    })
+}
 
-    resp, err := s.client.Do(req)
+func (s *RedditScraper) fetchFromURL(ctx context.Context, url string) (Extraction, error) {
+    resp, err := s.http.get(ctx, url, nil)
     if err != nil {
-        return nil, err
+        return Extraction{}, err
     }
     defer resp.Body.Close()
 
     if resp.StatusCode != http.StatusOK {
-        return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
+        return Extraction{}, fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
     }
 
     doc, err := goquery.NewDocumentFromReader(resp.Body)
     if err != nil {
-        return nil, err
+        return Extraction{}, err
     }
 
-    var posts []storage.Post
+    var extraction Extraction
 
-    // Find post elements (this selector might need adjustment based on Reddit's current HTML structure)
-    doc.Find("div[data-testid='post-container']").Each(func(i int, postEl *goquery.Selection) {
+    // Current Reddit renders <shreddit-post> elements; older markup used
+    // post-container divs
+    doc.Find("div[data-testid='post-container'], shreddit-post").Each(func(i int, postEl *goquery.Selection) {
+        extraction.Containers++
         post := s.extractPost(postEl)
-        if post != nil && s.meetsThreshold(*post) {
-            posts = append(posts, *post)
+        if post != nil {
+            extraction.Posts = append(extraction.Posts, *post)
+        } else {
+            extraction.Skipped++
         }
     })
 
-    return posts, nil
+    s.http.remember(ctx, resp)
+    return extraction, nil
 }
 
 func (s *RedditScraper) extractPost(postEl *goquery.Selection) *storage.Post {
@@ -94,24 +92,97 @@ func (s *RedditScraper) extractPost(postEl *goquery.Selection) *storage.Post {
     // Extract title
     titleEl := postEl.Find("h3[data-testid='post-title']")
     title := strings.TrimSpace(titleEl.Text())
+    if title == "" {
+        title = strings.TrimSpace(postEl.AttrOr("post-title", ""))
+    }
     if title == "" {
         return nil
     }
 
     // Extract body text
-    bodyEl := postEl.Find("div[data-testid='post-content'] p")
+    bodyEl := postEl.Find("div[data-testid='post-content'] p, div[slot='text-body'] p")
     body := strings.TrimSpace(bodyEl.Text())
 
-    // Extract media URLs
-    mediaURLs := s.extractMediaURLs(postEl)
+    media := s.extractMedia(postEl)
+    shreddit := s.shredditPost(postEl)
+
+    permalink := postEl.AttrOr("permalink", "")
+    if strings.HasPrefix(permalink, "/") {
+        permalink = redditBaseURL + permalink
+    }
 
     return &storage.Post{
-        RedditID:  redditID,
-        Title:     title,
-        Body:      body,
-        MediaURLs: mediaURLs,
-        CreatedAt: time.Now(),
+        RedditID:    redditID,
+        Title:       title,
+        Body:        body,
+        Score:       s.extractUpvotes(postEl),
+        NumComments: s.extractCommentCount(postEl),
+        Permalink:   permalink,
+        Author:      postEl.AttrOr("author", ""),
+        Subreddit:   postEl.AttrOr("subreddit-name", ""),
+        NSFW:        hasFlag(shreddit, "nsfw"),
+        Spoiler:     hasFlag(shreddit, "spoiler"),
+        Stickied:    hasFlag(shreddit, "stickied"),
+        MediaURLs:   mediaURLs(media),
+        Media:       media,
+        CreatedAt:   s.extractCreatedAt(postEl),
+    }
+}
+
+// shredditPost returns the post's shreddit-post element: the container
+// itself in current markup, or one around or inside a post-container div.
+// It carries the post's flags as nsfw, spoiler and stickied attributes.
+func (s *RedditScraper) shredditPost(postEl *goquery.Selection) *goquery.Selection {
+    if el := postEl.Closest("shreddit-post"); el.Length() > 0 {
+        return el
     }
+    return postEl.Find("shreddit-post").First()
+}
+
+// hasFlag reports whether a boolean attribute is set and not "false"
+func hasFlag(el *goquery.Selection, name string) bool {
+    value, exists := el.Attr(name)
+    return exists && value != "false"
+}
+
+func (s *RedditScraper) extractCreatedAt(postEl *goquery.Selection) time.Time {
+    // Newer markup carries an RFC 3339 timestamp on the container
+    if ts, exists := postEl.Attr("created-timestamp"); exists {
+        // shreddit-post writes the offset without a colon
+        for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05.999999-0700"} {
+            if createdAt, err := time.Parse(layout, ts); err == nil {
+                return createdAt.UTC()
+            }
+        }
+    }
+
+    for _, sel := range []string{"faceplate-timeago[ts]", "time[datetime]"} {
+        el := postEl.Find(sel).First()
+        ts, exists := el.Attr("ts")
+        if !exists {
+            ts, exists = el.Attr("datetime")
+        }
+        if !exists {
+            continue
+        }
+        if createdAt, err := time.Parse(time.RFC3339, ts); err == nil {
+            return createdAt.UTC()
+        }
+    }
+
+    // Without a timestamp the post is treated as brand new
+    return time.Now()
+}
+
+func (s *RedditScraper) extractCommentCount(postEl *goquery.Selection) int {
+    if value, exists := postEl.Attr("comment-count"); exists {
+        if comments, ok := parseScore(value); ok {
+            return comments
+        }
+    }
+
+    comments, _ := parseScore(postEl.Find("span[data-testid='comment-count']").First().Text())
+    return comments
 }
 
 func (s *RedditScraper) extractRedditID(postEl *goquery.Selection) string {
@@ -120,9 +191,18 @@ func (s *RedditScraper) extractRedditID(postEl *goquery.Selection) string {
         return id
     }
 
+    // shreddit-post carries the fullname, t3_<id>
+    if id, exists := postEl.Attr("id"); exists && strings.HasPrefix(id, "t3_") {
+        return strings.TrimPrefix(id, "t3_")
+    }
+
     // Try to extract from permalink URL
     linkEl := postEl.Find("a[data-testid='post-title']")
-    if href, exists := linkEl.Attr("href"); exists {
+    href, exists := linkEl.Attr("href")
+    if !exists {
+        href, exists = postEl.Attr("permalink")
+    }
+    if exists {
         re := regexp.MustCompile(`/comments/([a-zA-Z0-9]+)/`)
         matches := re.FindStringSubmatch(href)
         if len(matches) > 1 {
@@ -133,41 +213,113 @@ func (s *RedditScraper) extractRedditID(postEl *goquery.Selection) string {
     return ""
 }
 
-func (s *RedditScraper) extractMediaURLs(postEl *goquery.Selection) []string {
-    var urls []string
+// decorativeImage matches avatars, award icons, emoji and other chrome
+// that share the post container with its real media
+var decorativeImage = regexp.MustCompile(`(?i)avatar|award|icon|emoji|snoo|flair|redditstatic\.com|styles\.redditmedia\.com`)
+
+func (s *RedditScraper) extractMedia(postEl *goquery.Selection) []storage.Media {
+    var media []storage.Media
+    seen := make(map[string]bool)
+
+    add := func(el *goquery.Selection, src, mediaType string) {
+        if !strings.HasPrefix(src, "http") || seen[src] {
+            return
+        }
+        seen[src] = true
+
+        width, _ := strconv.Atoi(el.AttrOr("width", ""))
+        height, _ := strconv.Atoi(el.AttrOr("height", ""))
+        media = append(media, storage.Media{URL: unescapeMediaURL(src), Type: mediaType, Width: width, Height: height})
+    }
 
-    // Extract image URLs
     postEl.Find("img").Each(func(i int, imgEl *goquery.Selection) {
-        if src, exists := imgEl.Attr("src"); exists && strings.HasPrefix(src, "http") {
-            urls = append(urls, src)
+        src := imgEl.AttrOr("src", "")
+        if decorativeImage.MatchString(src) || decorativeImage.MatchString(imgEl.AttrOr("class", "")) {
+            return
+        }
+
+        mediaType := storage.MediaImage
+        if t, ok := imageExtensions[strings.ToLower(path.Ext(strings.SplitN(src, "?", 2)[0]))]; ok {
+            mediaType = t
         }
+        add(imgEl, src, mediaType)
     })
 
-    // Extract video URLs
     postEl.Find("video").Each(func(i int, videoEl *goquery.Selection) {
-        if src, exists := videoEl.Attr("src"); exists && strings.HasPrefix(src, "http") {
-            urls = append(urls, src)
+        src := videoEl.AttrOr("src", "")
+        if src == "" {
+            src = videoEl.Find("source").First().AttrOr("src", "")
         }
+        add(videoEl, src, storage.MediaVideo)
     })
 
-    return urls
-}
-
-func (s *RedditScraper) meetsThreshold(post storage.Post) bool {
-    // For now, we'll assume all posts meet the threshold
-    // In a real implementation, you'd extract upvote count and compare
-    return true
+    return media
 }
 
 func (s *RedditScraper) extractUpvotes(postEl *goquery.Selection) int {
+    // Newer markup exposes the score as an attribute on the container
+    for _, attr := range []string{"score", "data-score"} {
+        if value, exists := postEl.Attr(attr); exists {
+            if upvotes, ok := parseScore(value); ok {
+                return upvotes
+            }
+        }
+    }
+
     upvoteEl := postEl.Find("span[data-testid='upvote-count']")
-    upvoteText := strings.TrimSpace(upvoteEl.Text())
-    
-    // Clean up the text (remove 'k', 'M' suffixes, etc.)
-    upvoteText = strings.ReplaceAll(upvoteText, "k", "000")
-    upvoteText = strings.ReplaceAll(upvoteText, "M", "000000")
-    upvoteText = regexp.MustCompile(`[^\d]`).ReplaceAllString(upvoteText, "")
-    
-    upvotes, _ := strconv.Atoi(upvoteText)
+    upvotes, _ := parseScore(upvoteEl.First().Text())
     return upvotes
-}
\ No newline at end of file
+}
+
+var scorePattern = regexp.MustCompile(`^(-?\d+(?:\.\d+)?)\s*([kKmM]?)`)
+
+// parseScore parses vote counts as Reddit displays them: "87", "1,204",
+// "1.2k", "15.3k" or "2M"
+func parseScore(text string) (int, bool) {
+    text = strings.ReplaceAll(strings.TrimSpace(text), ",", "")
+
+    matches := scorePattern.FindStringSubmatch(text)
+    if matches == nil {
+        return 0, false
+    }
+
+    value, err := strconv.ParseFloat(matches[1], 64)
+    if err != nil {
+        return 0, false
+    }
+
+    switch strings.ToLower(matches[2]) {
+    case "k":
+        value *= 1_000
+    case "m":
+        value *= 1_000_000
+    }
+
+    return int(math.Round(value)), true
+}
+
+// sourcesFromURLs builds sources sharing a single upvote threshold
+func sourcesFromURLs(urls []string, upvoteThreshold int) []config.Source {
+    sources := make([]config.Source, 0, len(urls))
+    for _, url := range urls {
+        sources = append(sources, config.Source{URL: url, UpvoteThreshold: upvoteThreshold})
+    }
+    return sources
+}
+
+// applyThreshold drops posts scoring below the source's upvote threshold
+// and reports how many were dropped
+func applyThreshold(source config.Source, posts []storage.Post) ([]storage.Post, int) {
+    var kept []storage.Post
+    below := 0
+
+    for _, post := range posts {
+        if post.Score < source.UpvoteThreshold {
+            below++
+            continue
+        }
+        kept = append(kept, post)
+    }
+
+    return kept, below
+}
@@ -4,12 +4,21 @@ import (
     "context"
     "net/http"
     "net/http/httptest"
+    "strings"
     "testing"
+    "time"
 
     "github.com/stretchr/testify/assert"
     "github.com/stretchr/testify/require"
+    "github.com/w1zzzle/ai-newsbot/internal/config"
+    "github.com/w1zzzle/ai-newsbot/internal/storage"
 )
 
+// redditFixtures holds hand-written Reddit pages in the format recorded by
+// WithFixtures; replace them with validate-source -record captures when
+// Reddit's markup changes
+const redditFixtures = "testdata/fixtures/reddit"
+
 func TestRedditScraper_FetchPosts(t *testing.T) {
     // Create a mock HTTP server
     server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -22,12 +31,18 @@ func TestRedditScraper_FetchPosts(t *testing.T) {
                         <p>This is a test post about artificial intelligence.</p>
                     </div>
                     <img src="https://example.com/image.jpg" alt="test image">
+                    <span data-testid="upvote-count">1.2k</span>
                 </div>
                 <div data-testid="post-container" data-post-id="test456">
                     <h3 data-testid="post-title">Another AI Post</h3>
                     <div data-testid="post-content">
                         <p>Another interesting AI development.</p>
                     </div>
+                    <span data-testid="upvote-count">150</span>
+                </div>
+                <div data-testid="post-container" data-post-id="test789">
+                    <h3 data-testid="post-title">Low-scoring AI Post</h3>
+                    <span data-testid="upvote-count">12</span>
                 </div>
             </body>
         </html>`
@@ -48,11 +63,170 @@ func TestRedditScraper_FetchPosts(t *testing.T) {
     assert.Equal(t, "Test AI News Title", posts[0].Title)
     assert.Equal(t, "This is a test post about artificial intelligence.", posts[0].Body)
     assert.Contains(t, posts[0].MediaURLs, "https://example.com/image.jpg")
+    assert.Equal(t, 1200, posts[0].Score)
 
     // Verify second post
     assert.Equal(t, "test456", posts[1].RedditID)
     assert.Equal(t, "Another AI Post", posts[1].Title)
     assert.Equal(t, "Another interesting AI development.", posts[1].Body)
+    assert.Equal(t, 150, posts[1].Score)
+}
+
+func TestRedditScraper_FetchPosts_Replay(t *testing.T) {
+    scraper := New([]string{"https://www.reddit.com/r/LocalLLaMA/"}, 0, WithFixtures(FixtureReplay, redditFixtures))
+
+    results := scraper.FetchResults(context.Background())
+    require.Len(t, results, 1)
+    require.NoError(t, results[0].Err)
+    assert.Equal(t, 3, results[0].Containers)
+    assert.Zero(t, results[0].Skipped)
+
+    posts := results[0].Posts
+    require.Len(t, posts, 3)
+
+    assert.Equal(t, "1eaq6o4", posts[0].RedditID)
+    assert.Equal(t, "Llama 3.1 405B quantized to 2 bits runs on a single 48GB GPU", posts[0].Title)
+    assert.True(t, strings.HasPrefix(posts[0].Body, "Perplexity goes up by about 8%"))
+    assert.Equal(t, 1296, posts[0].Score)
+    assert.Equal(t, 184, posts[0].NumComments)
+    assert.Equal(t, "https://www.reddit.com/r/LocalLLaMA/comments/1eaq6o4/llama_31_405b_quantized_to_2_bits_runs_on_a/", posts[0].Permalink)
+    assert.Equal(t, "quant_enjoyer", posts[0].Author)
+    assert.Equal(t, "LocalLLaMA", posts[0].Subreddit)
+    assert.Equal(t, time.Date(2024, 7, 24, 9, 12, 45, 318000000, time.UTC), posts[0].CreatedAt)
+    // Avatars and the subreddit icon are not post media
+    assert.Empty(t, posts[0].Media)
+
+    assert.Equal(t, "1eas2kq", posts[1].RedditID)
+    assert.Equal(t, 2100, posts[1].NumComments)
+    assert.Equal(t, []storage.Media{
+        {URL: "https://i.redd.it/k8v4ll0coded.png", Type: storage.MediaImage, Width: 1200, Height: 675},
+    }, posts[1].Media)
+
+    assert.Equal(t, "1eajx00", posts[2].RedditID)
+    assert.Equal(t, 12, posts[2].Score)
+}
+
+func TestJSONScraper_FetchPosts_Replay(t *testing.T) {
+    scraper := NewJSONFromSources([]config.Source{
+        {URL: "https://www.reddit.com/r/MachineLearning/top/?t=day", PageDepth: 1},
+    }, WithFixtures(FixtureReplay, redditFixtures))
+
+    posts, err := scraper.FetchPosts(context.Background())
+    require.NoError(t, err)
+    require.Len(t, posts, 3)
+    assert.Equal(t, "1b9xq2a", posts[0].RedditID)
+    assert.Equal(t, []string{"https://i.redd.it/q7z4m2benchmark.png"}, posts[1].MediaURLs)
+}
+
+func TestRedditScraper_FetchPosts_PerSourceThreshold(t *testing.T) {
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.WriteHeader(http.StatusOK)
+        w.Write([]byte(`<html><body>
+            <div data-testid="post-container" data-post-id="busy1" data-score="15.3k">
+                <h3 data-testid="post-title">Busy subreddit post</h3>
+            </div>
+            <div data-testid="post-container" data-post-id="busy2" data-score="480">
+                <h3 data-testid="post-title">Quiet post on a busy subreddit</h3>
+            </div>
+        </body></html>`))
+    }))
+    defer server.Close()
+
+    scraper := NewFromSources([]config.Source{
+        {URL: server.URL + "/r/busy/", UpvoteThreshold: 1000},
+        {URL: server.URL + "/r/niche/", UpvoteThreshold: 100},
+    })
+
+    posts, err := scraper.FetchPosts(context.Background())
+    require.NoError(t, err)
+    require.Len(t, posts, 3)
+    assert.Equal(t, "busy1", posts[0].RedditID)
+    assert.Equal(t, 15300, posts[0].Score)
+    assert.Equal(t, "busy1", posts[1].RedditID)
+    assert.Equal(t, "busy2", posts[2].RedditID)
+}
+
+func TestRedditScraper_FetchPosts_CreatedAtAndComments(t *testing.T) {
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.WriteHeader(http.StatusOK)
+        w.Write([]byte(`<html><body>
+            <div data-testid="post-container" data-post-id="dated1" data-score="250" comment-count="1.1k"
+                 created-timestamp="2024-03-11T13:00:00+00:00">
+                <h3 data-testid="post-title">Post with attribute timestamp</h3>
+            </div>
+            <div data-testid="post-container" data-post-id="dated2" data-score="250">
+                <h3 data-testid="post-title">Post with timeago element</h3>
+                <faceplate-timeago ts="2024-03-11T09:30:00.000Z"></faceplate-timeago>
+                <span data-testid="comment-count">42</span>
+            </div>
+        </body></html>`))
+    }))
+    defer server.Close()
+
+    posts, err := New([]string{server.URL}, 100).FetchPosts(context.Background())
+    require.NoError(t, err)
+    require.Len(t, posts, 2)
+
+    assert.Equal(t, time.Date(2024, 3, 11, 13, 0, 0, 0, time.UTC), posts[0].CreatedAt)
+    assert.Equal(t, 1100, posts[0].NumComments)
+    assert.Equal(t, time.Date(2024, 3, 11, 9, 30, 0, 0, time.UTC), posts[1].CreatedAt)
+    assert.Equal(t, 42, posts[1].NumComments)
+}
+
+func TestRedditScraper_FetchResults_CountsBrokenContainers(t *testing.T) {
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.WriteHeader(http.StatusOK)
+        w.Write([]byte(`
+        <html>
+            <body>
+                <div data-testid="post-container" data-post-id="ok1">
+                    <h3 data-testid="post-title">Still parses</h3>
+                </div>
+                <div data-testid="post-container" data-post-id="new1">
+                    <h2 class="renamed-title">Markup changed</h2>
+                </div>
+                <div data-testid="post-container">
+                    <h3 data-testid="post-title">No ID</h3>
+                </div>
+            </body>
+        </html>`))
+    }))
+    defer server.Close()
+
+    results := New([]string{server.URL}, 0).FetchResults(context.Background())
+    require.Len(t, results, 1)
+    require.NoError(t, results[0].Err)
+
+    assert.Len(t, results[0].Posts, 1)
+    assert.Equal(t, 3, results[0].Containers)
+    assert.Equal(t, 2, results[0].Skipped)
+}
+
+func TestParseScore(t *testing.T) {
+    testCases := []struct {
+        input    string
+        expected int
+        ok       bool
+    }{
+        {"87", 87, true},
+        {"1,204", 1204, true},
+        {"1.2k", 1200, true},
+        {"15.3k", 15300, true},
+        {"15.3K", 15300, true},
+        {"2M", 2000000, true},
+        {"1.5m", 1500000, true},
+        {" 342 upvotes ", 342, true},
+        {"-4", -4, true},
+        {"Vote", 0, false},
+        {"•", 0, false},
+        {"", 0, false},
+    }
+
+    for _, tc := range testCases {
+        result, ok := parseScore(tc.input)
+        assert.Equal(t, tc.ok, ok, "input: %q", tc.input)
+        assert.Equal(t, tc.expected, result, "input: %q", tc.input)
+    }
 }
 
 func TestRedditScraper_FetchPosts_InvalidURL(t *testing.T) {
@@ -0,0 +1,216 @@
+package scraper
+
+import (
+    "context"
+    "crypto/tls"
+    "crypto/x509"
+    "fmt"
+    "log"
+    "net/http"
+    "net/url"
+    "os"
+    "sync"
+    "time"
+
+    "github.com/w1zzzle/ai-newsbot/internal/config"
+)
+
+// WithUserAgent replaces the User-Agent sent to sources that don't set
+// their own
+func WithUserAgent(userAgent string) Option {
+    return func(f *fetcher) {
+        if userAgent != "" {
+            f.userAgent = userAgent
+        }
+    }
+}
+
+// WithTimeout limits how long a single request may take
+func WithTimeout(d time.Duration) Option {
+    return func(f *fetcher) {
+        if d > 0 {
+            f.client.Timeout = d
+        }
+    }
+}
+
+type sourceKey struct{}
+
+// withSource marks ctx as fetching source, so requests made on its behalf
+// use its HTTP settings
+func withSource(ctx context.Context, source config.Source) context.Context {
+    return context.WithValue(ctx, sourceKey{}, source)
+}
+
+func sourceFrom(ctx context.Context) (config.Source, bool) {
+    source, ok := ctx.Value(sourceKey{}).(config.Source)
+    return source, ok
+}
+
+// sourceTransport sends requests through the proxies and TLS settings of
+// the source they are made for. Requests of sources without such settings
+// go through base.
+type sourceTransport struct {
+    base http.RoundTripper
+
+    mu     sync.Mutex
+    routes map[string]*proxyRoute
+}
+
+// proxyRoute holds one transport per proxy of a source and the one in use
+type proxyRoute struct {
+    transports []*http.Transport
+
+    mu      sync.Mutex
+    current int
+}
+
+func newSourceTransport() *sourceTransport {
+    return &sourceTransport{
+        base:   http.DefaultTransport,
+        routes: make(map[string]*proxyRoute),
+    }
+}
+
+func (t *sourceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+    source, ok := sourceFrom(req.Context())
+    if !ok || !source.HTTP.Custom() {
+        return t.base.RoundTrip(req)
+    }
+
+    route, err := t.route(source)
+    if err != nil {
+        return nil, err
+    }
+    return route.transport().RoundTrip(req)
+}
+
+// route returns the transports of source, building them on first use
+func (t *sourceTransport) route(source config.Source) (*proxyRoute, error) {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+
+    if route, ok := t.routes[source.URL]; ok {
+        return route, nil
+    }
+
+    route, err := newProxyRoute(source.HTTP)
+    if err != nil {
+        return nil, fmt.Errorf("failed to set up transport for %s: %w", source.URL, err)
+    }
+    t.routes[source.URL] = route
+    return route, nil
+}
+
+// rotate moves the source fetched in ctx on to its next proxy. It reports
+// false when the source has no other proxy to move to.
+func (t *sourceTransport) rotate(ctx context.Context) bool {
+    source, ok := sourceFrom(ctx)
+    if !ok || len(source.HTTP.Proxies) < 2 {
+        return false
+    }
+
+    route, err := t.route(source)
+    if err != nil {
+        return false
+    }
+
+    route.mu.Lock()
+    route.current = (route.current + 1) % len(route.transports)
+    current := route.current
+    route.mu.Unlock()
+
+    log.Printf("Switching %s to proxy %d of %d", source.URL, current+1, len(route.transports))
+    return true
+}
+
+// poolSize is the number of proxies the source fetched in ctx rotates through
+func (t *sourceTransport) poolSize(ctx context.Context) int {
+    source, ok := sourceFrom(ctx)
+    if !ok {
+        return 0
+    }
+    return len(source.HTTP.Proxies)
+}
+
+func (r *proxyRoute) transport() *http.Transport {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    return r.transports[r.current]
+}
+
+func newProxyRoute(settings config.HTTPSettings) (*proxyRoute, error) {
+    tlsConfig, err := newTLSConfig(settings.TLS)
+    if err != nil {
+        return nil, err
+    }
+
+    newTransport := func() *http.Transport {
+        transport := http.DefaultTransport.(*http.Transport).Clone()
+        if tlsConfig != nil {
+            transport.TLSClientConfig = tlsConfig
+        }
+        return transport
+    }
+
+    if len(settings.Proxies) == 0 {
+        return &proxyRoute{transports: []*http.Transport{newTransport()}}, nil
+    }
+
+    route := &proxyRoute{}
+    for _, proxy := range settings.Proxies {
+        proxyURL, err := url.Parse(proxy)
+        if err != nil {
+            return nil, fmt.Errorf("invalid proxy %q: %w", proxy, err)
+        }
+        transport := newTransport()
+        transport.Proxy = http.ProxyURL(proxyURL)
+        route.transports = append(route.transports, transport)
+    }
+    return route, nil
+}
+
+// newTLSConfig builds the TLS config of a source, or nil when it keeps
+// the defaults
+func newTLSConfig(settings config.TLSSettings) (*tls.Config, error) {
+    if settings == (config.TLSSettings{}) {
+        return nil, nil
+    }
+
+    cfg := &tls.Config{
+        ServerName:         settings.ServerName,
+        InsecureSkipVerify: settings.InsecureSkipVerify,
+    }
+
+    switch settings.MinVersion {
+    case "1.2":
+        cfg.MinVersion = tls.VersionTLS12
+    case "1.3":
+        cfg.MinVersion = tls.VersionTLS13
+    }
+
+    if settings.CAFile != "" {
+        pem, err := os.ReadFile(settings.CAFile)
+        if err != nil {
+            return nil, fmt.Errorf("failed to read CA file: %w", err)
+        }
+        pool, err := x509.SystemCertPool()
+        if err != nil {
+            pool = x509.NewCertPool()
+        }
+        if !pool.AppendCertsFromPEM(pem) {
+            return nil, fmt.Errorf("no certificates found in %s", settings.CAFile)
+        }
+        cfg.RootCAs = pool
+    }
+
+    if settings.CertFile != "" {
+        cert, err := tls.LoadX509KeyPair(settings.CertFile, settings.KeyFile)
+        if err != nil {
+            return nil, fmt.Errorf("failed to load client certificate: %w", err)
+        }
+        cfg.Certificates = []tls.Certificate{cert}
+    }
+
+    return cfg, nil
+}
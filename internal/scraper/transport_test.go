@@ -0,0 +1,131 @@
+package scraper
+
+import (
+    "context"
+    "encoding/pem"
+    "net/http"
+    "net/http/httptest"
+    "os"
+    "path/filepath"
+    "sync/atomic"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "github.com/w1zzzle/ai-newsbot/internal/config"
+)
+
+func TestFetcher_Get_SourceHeaders(t *testing.T) {
+    var got http.Header
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        got = r.Header.Clone()
+    }))
+    defer server.Close()
+
+    f := newFetcher(WithUserAgent("newsbot-test/1.0"))
+
+    resp, err := f.get(context.Background(), server.URL, nil)
+    require.NoError(t, err)
+    resp.Body.Close()
+    assert.Equal(t, "newsbot-test/1.0", got.Get("User-Agent"))
+
+    ctx := withSource(context.Background(), config.Source{
+        URL: server.URL,
+        HTTP: config.HTTPSettings{
+            UserAgent: "source-agent/2.0",
+            Headers:   map[string]string{"Accept-Language": "en", "Accept": "text/plain"},
+        },
+    })
+    resp, err = f.get(ctx, server.URL, http.Header{"Accept": {"application/json"}})
+    require.NoError(t, err)
+    resp.Body.Close()
+
+    assert.Equal(t, "source-agent/2.0", got.Get("User-Agent"))
+    assert.Equal(t, "en", got.Get("Accept-Language"))
+    // The scraper's own Accept header wins over the source's
+    assert.Equal(t, "application/json", got.Get("Accept"))
+}
+
+func TestFetcher_Get_RotatesProxiesWhenBlocked(t *testing.T) {
+    // Plain HTTP proxies receive the absolute URL and answer themselves
+    var blockedCalls, openCalls int32
+    blocked := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        atomic.AddInt32(&blockedCalls, 1)
+        w.WriteHeader(http.StatusForbidden)
+    }))
+    defer blocked.Close()
+    open := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        atomic.AddInt32(&openCalls, 1)
+        assert.Equal(t, "www.reddit.com", r.Host)
+        w.Write([]byte("ok"))
+    }))
+    defer open.Close()
+
+    source := config.Source{
+        URL:  "http://www.reddit.com/r/LocalLLaMA/",
+        HTTP: config.HTTPSettings{Proxies: []string{blocked.URL, open.URL}},
+    }
+    f := newFetcher()
+    ctx := withSource(context.Background(), source)
+
+    resp, err := f.get(ctx, source.URL, nil)
+    require.NoError(t, err)
+    resp.Body.Close()
+    assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+    // The next request stays on the proxy that worked
+    resp, err = f.get(ctx, source.URL, nil)
+    require.NoError(t, err)
+    resp.Body.Close()
+
+    assert.Equal(t, int32(1), atomic.LoadInt32(&blockedCalls))
+    assert.Equal(t, int32(2), atomic.LoadInt32(&openCalls))
+}
+
+func TestFetcher_Get_GivesUpWhenEveryProxyIsBlocked(t *testing.T) {
+    var calls int32
+    blocked := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        atomic.AddInt32(&calls, 1)
+        w.WriteHeader(http.StatusForbidden)
+    }))
+    defer blocked.Close()
+
+    source := config.Source{
+        URL:  "http://www.reddit.com/r/LocalLLaMA/",
+        HTTP: config.HTTPSettings{Proxies: []string{blocked.URL, blocked.URL, blocked.URL}},
+    }
+    f := newFetcher()
+
+    resp, err := f.get(withSource(context.Background(), source), source.URL, nil)
+    require.NoError(t, err)
+    resp.Body.Close()
+
+    assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+    assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestFetcher_Get_SourceCAFile(t *testing.T) {
+    server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.Write([]byte("ok"))
+    }))
+    defer server.Close()
+
+    f := newFetcher()
+
+    // The test server's certificate isn't trusted by default
+    _, err := f.get(context.Background(), server.URL, nil)
+    require.Error(t, err)
+
+    caFile := filepath.Join(t.TempDir(), "ca.pem")
+    cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+    require.NoError(t, os.WriteFile(caFile, cert, 0o644))
+
+    ctx := withSource(context.Background(), config.Source{
+        URL:  server.URL,
+        HTTP: config.HTTPSettings{TLS: config.TLSSettings{CAFile: caFile, MinVersion: "1.2"}},
+    })
+    resp, err := f.get(ctx, server.URL, nil)
+    require.NoError(t, err)
+    resp.Body.Close()
+    assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
@@ -2,8 +2,10 @@ package storage
 
 import (
     "context"
+    "errors"
     "time"
 
+    "github.com/jackc/pgx/v5"
     "github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -12,17 +14,126 @@ type Post struct {
     RedditID      string    `json:"reddit_id"`
     Title         string    `json:"title"`
     Body          string    `json:"body"`
+    Score         int       `json:"score"`
+    NumComments   int       `json:"num_comments"`
+    Permalink     string    `json:"permalink"`
+    // URL is the external page a link post points to, if any
+    URL           string    `json:"url"`
+    // CanonicalURL and TitleHash identify the story across sources
+    CanonicalURL  string    `json:"canonical_url"`
+    TitleHash     int64     `json:"title_hash"`
+    // CrosspostParent is the ID of the Reddit post this one crossposts
+    CrosspostParent string  `json:"crosspost_parent"`
+    Author        string    `json:"author"`
+    Subreddit     string    `json:"subreddit"`
+    // Flair and the flags below come from Reddit and are only used to
+    // filter posts before they are saved
+    Flair         string    `json:"flair"`
+    NSFW          bool      `json:"nsfw"`
+    Spoiler       bool      `json:"spoiler"`
+    Stickied      bool      `json:"stickied"`
+    IsSelf        bool      `json:"is_self"`
+    // RemovedBy is why a moderator or Reddit removed the post, if it was
+    RemovedBy     string    `json:"removed_by"`
     MediaURLs     []string  `json:"media_urls"`
+    // Media describes the entries of MediaURLs when the source knows their
+    // type and size
+    Media         []Media   `json:"media"`
+    // TopComments are the highest-scoring replies, quoted under the post
+    TopComments   []Comment `json:"top_comments"`
+    // Translations holds one translation per language code
+    Translations  map[string]Translation `json:"translations"`
+    // TranslatedBody is the translation being published, filled in by
+    // ListUnpublishedPosts for its language
     TranslatedBody string   `json:"translated_body"`
+    // Lang is the language code of TranslatedBody, set by UseTranslation
+    Lang          string    `json:"lang"`
     PublishedAt   *time.Time `json:"published_at"`
+    // CreatedAt is when the post was created at its source
     CreatedAt     time.Time `json:"created_at"`
 }
 
+// Media types
+const (
+    MediaImage = "image"
+    MediaGIF   = "gif"
+    MediaVideo = "video"
+)
+
+// Media is a picture or clip attached to a post
+type Media struct {
+    URL  string `json:"url"`
+    Type string `json:"type"`
+    // Width and Height are in pixels, zero when unknown
+    Width  int `json:"width,omitempty"`
+    Height int `json:"height,omitempty"`
+}
+
+// Comment is a reply to a post
+type Comment struct {
+    Author         string `json:"author"`
+    Body           string `json:"body"`
+    Score          int    `json:"score"`
+    TranslatedBody string `json:"translated_body"`
+}
+
+// Translation is a post translated into one language. Comments[i]
+// translates TopComments[i] and is empty when that comment failed.
+type Translation struct {
+    Body     string   `json:"body"`
+    Comments []string `json:"comments"`
+}
+
+// Empty reports whether nothing of the post was translated
+func (t Translation) Empty() bool {
+    if t.Body != "" {
+        return false
+    }
+    for _, comment := range t.Comments {
+        if comment != "" {
+            return false
+        }
+    }
+    return true
+}
+
+// UseTranslation fills TranslatedBody and the translated comments from the
+// post's translation into lang, clearing them if there is none
+func (p *Post) UseTranslation(lang string) {
+    t := p.Translations[lang]
+    p.Lang = lang
+    p.TranslatedBody = t.Body
+    for i := range p.TopComments {
+        p.TopComments[i].TranslatedBody = ""
+        if i < len(t.Comments) {
+            p.TopComments[i].TranslatedBody = t.Comments[i]
+        }
+    }
+}
+
+// HTTPValidators are the ETag and Last-Modified values last returned for a
+// fetched URL, used for conditional GET requests
+type HTTPValidators struct {
+    URL          string
+    ETag         string
+    LastModified string
+}
+
+// SourceRun is what a source yielded in one pipeline run
+type SourceRun struct {
+    Source string
+    // Posts counts the posts extracted, before any threshold is applied
+    Posts      int
+    Containers int
+    Skipped    int
+    RanAt      time.Time
+}
+
 type Store interface {
     SavePost(ctx context.Context, p Post) error
     IsPostSeen(ctx context.Context, redditID string) (bool, error)
-    ListUnpublishedPosts(ctx context.Context) ([]Post, error)
-    MarkPublished(ctx context.Context, redditID string) error
+    ListUnpublishedPosts(ctx context.Context, lang string) ([]Post, error)
+    MarkPublished(ctx context.Context, redditID, lang string) error
     Close() error
 }
 
@@ -46,17 +157,67 @@ func NewPostgresStore(dsn string) (*PostgresStore, error) {
 
 func (s *PostgresStore) SavePost(ctx context.Context, p Post) error {
     query := `
-        INSERT INTO posts (reddit_id, title, body, media_urls, translated_body)
-        VALUES ($1, $2, $3, $4, $5)
+        INSERT INTO posts (reddit_id, title, body, score, num_comments, permalink, url, author, subreddit, media_urls, media, top_comments, created_at, canonical_url, title_hash)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
         ON CONFLICT (reddit_id) DO UPDATE SET
             title = EXCLUDED.title,
             body = EXCLUDED.body,
+            score = EXCLUDED.score,
+            num_comments = EXCLUDED.num_comments,
+            permalink = EXCLUDED.permalink,
+            url = EXCLUDED.url,
+            author = EXCLUDED.author,
+            subreddit = EXCLUDED.subreddit,
             media_urls = EXCLUDED.media_urls,
-            translated_body = EXCLUDED.translated_body
+            media = EXCLUDED.media,
+            top_comments = EXCLUDED.top_comments,
+            canonical_url = EXCLUDED.canonical_url,
+            title_hash = EXCLUDED.title_hash
     `
-    
-    _, err := s.pool.Exec(ctx, query, p.RedditID, p.Title, p.Body, p.MediaURLs, p.TranslatedBody)
-    return err
+
+    createdAt := p.CreatedAt
+    if createdAt.IsZero() {
+        createdAt = time.Now()
+    }
+
+    // Store empty arrays rather than JSON null
+    media := p.Media
+    if media == nil {
+        media = []Media{}
+    }
+    comments := p.TopComments
+    if comments == nil {
+        comments = []Comment{}
+    }
+
+    tx, err := s.pool.Begin(ctx)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback(ctx)
+
+    if _, err := tx.Exec(ctx, query, p.RedditID, p.Title, p.Body, p.Score, p.NumComments, p.Permalink, p.URL, p.Author, p.Subreddit, p.MediaURLs, media, comments, createdAt, p.CanonicalURL, p.TitleHash); err != nil {
+        return err
+    }
+
+    for lang, t := range p.Translations {
+        translatedComments := t.Comments
+        if translatedComments == nil {
+            translatedComments = []string{}
+        }
+        _, err := tx.Exec(ctx, `
+            INSERT INTO translations (reddit_id, lang, body, comments)
+            VALUES ($1, $2, $3, $4)
+            ON CONFLICT (reddit_id, lang) DO UPDATE SET
+                body = EXCLUDED.body,
+                comments = EXCLUDED.comments
+        `, p.RedditID, lang, t.Body, translatedComments)
+        if err != nil {
+            return err
+        }
+    }
+
+    return tx.Commit(ctx)
 }
 
 func (s *PostgresStore) IsPostSeen(ctx context.Context, redditID string) (bool, error) {
@@ -67,15 +228,21 @@ func (s *PostgresStore) IsPostSeen(ctx context.Context, redditID string) (bool,
     return exists, err
 }
 
-func (s *PostgresStore) ListUnpublishedPosts(ctx context.Context) ([]Post, error) {
+// ListUnpublishedPosts returns the posts translated into lang that have not
+// been published in it yet, with TranslatedBody and the comments filled in
+// from that translation
+func (s *PostgresStore) ListUnpublishedPosts(ctx context.Context, lang string) ([]Post, error) {
     query := `
-        SELECT id, reddit_id, title, body, media_urls, translated_body, published_at, created_at
-        FROM posts
-        WHERE published_at IS NULL AND translated_body IS NOT NULL AND translated_body != ''
-        ORDER BY created_at ASC
+        SELECT p.id, p.reddit_id, p.title, p.body, p.score, p.num_comments, p.permalink, p.url, p.author, p.subreddit, p.media_urls, p.media, p.top_comments, t.body, t.comments, p.published_at, p.created_at
+        FROM posts p
+        JOIN translations t ON t.reddit_id = p.reddit_id AND t.lang = $1
+        WHERE t.published_at IS NULL
+            AND p.duplicate_of IS NULL
+            AND (t.body != '' OR t.comments != '[]'::jsonb)
+        ORDER BY p.created_at ASC
     `
     
-    rows, err := s.pool.Query(ctx, query)
+    rows, err := s.pool.Query(ctx, query, lang)
     if err != nil {
         return nil, err
     }
@@ -84,11 +251,14 @@ func (s *PostgresStore) ListUnpublishedPosts(ctx context.Context) ([]Post, error
     var posts []Post
     for rows.Next() {
         var p Post
+        var t Translation
         
-        err := rows.Scan(&p.ID, &p.RedditID, &p.Title, &p.Body, &p.MediaURLs, &p.TranslatedBody, &p.PublishedAt, &p.CreatedAt)
+        err := rows.Scan(&p.ID, &p.RedditID, &p.Title, &p.Body, &p.Score, &p.NumComments, &p.Permalink, &p.URL, &p.Author, &p.Subreddit, &p.MediaURLs, &p.Media, &p.TopComments, &t.Body, &t.Comments, &p.PublishedAt, &p.CreatedAt)
         if err != nil {
             return nil, err
         }
+        p.Translations = map[string]Translation{lang: t}
+        p.UseTranslation(lang)
         
         posts = append(posts, p)
     }
@@ -96,12 +266,174 @@ func (s *PostgresStore) ListUnpublishedPosts(ctx context.Context) ([]Post, error
     return posts, rows.Err()
 }
 
-func (s *PostgresStore) MarkPublished(ctx context.Context, redditID string) error {
-    query := `UPDATE posts SET published_at = NOW() WHERE reddit_id = $1`
-    _, err := s.pool.Exec(ctx, query, redditID)
+// FindDuplicate returns the ID of the earliest saved post with the same
+// canonical URL, the post's crosspost parent, or a title hash within
+// maxDistance bits from the last three days
+func (s *PostgresStore) FindDuplicate(ctx context.Context, p Post, maxDistance int) (string, bool, error) {
+    // Postgres 12 has no bit_count, so differing bits are counted in the
+    // text form of the XOR
+    query := `
+        SELECT reddit_id FROM posts
+        WHERE duplicate_of IS NULL
+            AND reddit_id != $1
+            AND (
+                ($2 != '' AND canonical_url = $2)
+                OR ($3 != '' AND reddit_id = $3)
+                OR ($4 != 0 AND title_hash != 0
+                    AND created_at > NOW() - INTERVAL '3 days'
+                    AND length(replace(((title_hash # $4)::bit(64))::text, '0', '')) <= $5)
+            )
+        ORDER BY created_at ASC
+        LIMIT 1
+    `
+
+    var id string
+    err := s.pool.QueryRow(ctx, query, p.RedditID, p.CanonicalURL, p.CrosspostParent, p.TitleHash, maxDistance).Scan(&id)
+    if errors.Is(err, pgx.ErrNoRows) {
+        return "", false, nil
+    }
+    if err != nil {
+        return "", false, err
+    }
+    return id, true, nil
+}
+
+// SaveDuplicate records a post as a copy of the saved post originalID. It
+// counts as seen from then on but is never listed for publishing.
+func (s *PostgresStore) SaveDuplicate(ctx context.Context, p Post, originalID string) error {
+    query := `
+        INSERT INTO posts (reddit_id, title, score, num_comments, permalink, url, author, subreddit, canonical_url, title_hash, duplicate_of, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+        ON CONFLICT (reddit_id) DO NOTHING
+    `
+
+    createdAt := p.CreatedAt
+    if createdAt.IsZero() {
+        createdAt = time.Now()
+    }
+
+    _, err := s.pool.Exec(ctx, query, p.RedditID, p.Title, p.Score, p.NumComments, p.Permalink, p.URL, p.Author, p.Subreddit, p.CanonicalURL, p.TitleHash, originalID, createdAt)
+    return err
+}
+
+// GetPost returns a saved post by its ID, with TranslatedBody and the
+// comments filled in from its translation into lang when there is one
+func (s *PostgresStore) GetPost(ctx context.Context, redditID, lang string) (Post, bool, error) {
+    query := `
+        SELECT p.id, p.reddit_id, p.title, p.body, p.score, p.num_comments, p.permalink, p.url, p.author, p.subreddit, p.media_urls, p.media, p.top_comments, t.body, t.comments, p.published_at, p.created_at
+        FROM posts p
+        LEFT JOIN translations t ON t.reddit_id = p.reddit_id AND t.lang = $2
+        WHERE p.reddit_id = $1
+    `
+
+    var p Post
+    var body *string
+    var comments []string
+    err := s.pool.QueryRow(ctx, query, redditID, lang).Scan(&p.ID, &p.RedditID, &p.Title, &p.Body, &p.Score, &p.NumComments, &p.Permalink, &p.URL, &p.Author, &p.Subreddit, &p.MediaURLs, &p.Media, &p.TopComments, &body, &comments, &p.PublishedAt, &p.CreatedAt)
+    if errors.Is(err, pgx.ErrNoRows) {
+        return p, false, nil
+    }
+    if err != nil {
+        return p, false, err
+    }
+
+    // Without a translation row both columns are NULL
+    if body != nil {
+        p.Translations = map[string]Translation{lang: {Body: *body, Comments: comments}}
+    }
+    p.UseTranslation(lang)
+    return p, true, nil
+}
+
+// MarkPublished records that a post went out in lang. The post itself
+// keeps the time it was first published in any language.
+func (s *PostgresStore) MarkPublished(ctx context.Context, redditID, lang string) error {
+    tx, err := s.pool.Begin(ctx)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback(ctx)
+
+    if _, err := tx.Exec(ctx, `UPDATE translations SET published_at = NOW() WHERE reddit_id = $1 AND lang = $2`, redditID, lang); err != nil {
+        return err
+    }
+    if _, err := tx.Exec(ctx, `UPDATE posts SET published_at = COALESCE(published_at, NOW()) WHERE reddit_id = $1`, redditID); err != nil {
+        return err
+    }
+
+    return tx.Commit(ctx)
+}
+
+func (s *PostgresStore) GetHTTPValidators(ctx context.Context, url string) (HTTPValidators, bool, error) {
+    v := HTTPValidators{URL: url}
+    query := `SELECT etag, last_modified FROM http_cache WHERE url = $1`
+
+    err := s.pool.QueryRow(ctx, query, url).Scan(&v.ETag, &v.LastModified)
+    if errors.Is(err, pgx.ErrNoRows) {
+        return v, false, nil
+    }
+    if err != nil {
+        return v, false, err
+    }
+    return v, true, nil
+}
+
+func (s *PostgresStore) SaveHTTPValidators(ctx context.Context, v HTTPValidators) error {
+    query := `
+        INSERT INTO http_cache (url, etag, last_modified, updated_at)
+        VALUES ($1, $2, $3, NOW())
+        ON CONFLICT (url) DO UPDATE SET
+            etag = EXCLUDED.etag,
+            last_modified = EXCLUDED.last_modified,
+            updated_at = NOW()
+    `
+    _, err := s.pool.Exec(ctx, query, v.URL, v.ETag, v.LastModified)
+    return err
+}
+
+func (s *PostgresStore) RecordSourceRun(ctx context.Context, run SourceRun) error {
+    query := `
+        INSERT INTO source_runs (source, posts, containers, skipped, ran_at)
+        VALUES ($1, $2, $3, $4, $5)
+    `
+
+    ranAt := run.RanAt
+    if ranAt.IsZero() {
+        ranAt = time.Now()
+    }
+
+    _, err := s.pool.Exec(ctx, query, run.Source, run.Posts, run.Containers, run.Skipped, ranAt)
     return err
 }
 
+// RecentSourceRuns returns the last n runs of a source, newest first
+func (s *PostgresStore) RecentSourceRuns(ctx context.Context, source string, n int) ([]SourceRun, error) {
+    query := `
+        SELECT source, posts, containers, skipped, ran_at
+        FROM source_runs
+        WHERE source = $1
+        ORDER BY ran_at DESC
+        LIMIT $2
+    `
+
+    rows, err := s.pool.Query(ctx, query, source, n)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var runs []SourceRun
+    for rows.Next() {
+        var run SourceRun
+        if err := rows.Scan(&run.Source, &run.Posts, &run.Containers, &run.Skipped, &run.RanAt); err != nil {
+            return nil, err
+        }
+        runs = append(runs, run)
+    }
+
+    return runs, rows.Err()
+}
+
 func (s *PostgresStore) Close() error {
     s.pool.Close()
     return nil
@@ -75,7 +75,7 @@ func TestPostgresStore_MarkPublished(t *testing.T) {
 // MockStore for unit testing other components
 type MockStore struct {
     posts     map[string]Post
-    published map[string]bool
+    published map[string]bool // keyed by reddit ID and language
 }
 
 func NewMockStore() *MockStore {
@@ -95,18 +95,19 @@ func (m *MockStore) IsPostSeen(ctx context.Context, redditID string) (bool, erro
     return exists, nil
 }
 
-func (m *MockStore) ListUnpublishedPosts(ctx context.Context) ([]Post, error) {
+func (m *MockStore) ListUnpublishedPosts(ctx context.Context, lang string) ([]Post, error) {
     var unpublished []Post
     for _, post := range m.posts {
-        if !m.published[post.RedditID] && post.TranslatedBody != "" {
+        if t, ok := post.Translations[lang]; ok && !t.Empty() && !m.published[post.RedditID+"/"+lang] {
+            post.UseTranslation(lang)
             unpublished = append(unpublished, post)
         }
     }
     return unpublished, nil
 }
 
-func (m *MockStore) MarkPublished(ctx context.Context, redditID string) error {
-    m.published[redditID] = true
+func (m *MockStore) MarkPublished(ctx context.Context, redditID, lang string) error {
+    m.published[redditID+"/"+lang] = true
     return nil
 }
 
@@ -119,11 +120,14 @@ func TestMockStore(t *testing.T) {
     ctx := context.Background()
 
     post := Post{
-        RedditID:       "test123",
-        Title:          "Test Post",
-        Body:           "This is a test post",
-        TranslatedBody: "Это тестовый пост",
-        CreatedAt:      time.Now(),
+        RedditID:  "test123",
+        Title:     "Test Post",
+        Body:      "This is a test post",
+        CreatedAt: time.Now(),
+        Translations: map[string]Translation{
+            "ru": {Body: "Это тестовый пост"},
+            "uk": {Body: "Це тестовий пост"},
+        },
     }
 
     // Test SavePost
@@ -136,17 +140,51 @@ func TestMockStore(t *testing.T) {
     assert.True(t, seen)
 
     // Test ListUnpublishedPosts
-    unpublished, err := store.ListUnpublishedPosts(ctx)
+    unpublished, err := store.ListUnpublishedPosts(ctx, "ru")
     require.NoError(t, err)
     assert.Len(t, unpublished, 1)
     assert.Equal(t, "test123", unpublished[0].RedditID)
+    assert.Equal(t, "Это тестовый пост", unpublished[0].TranslatedBody)
 
     // Test MarkPublished
-    err = store.MarkPublished(ctx, "test123")
+    err = store.MarkPublished(ctx, "test123", "ru")
     require.NoError(t, err)
 
-    // Verify post is no longer unpublished
-    unpublished, err = store.ListUnpublishedPosts(ctx)
+    // Verify post is no longer unpublished in Russian, but still is in Ukrainian
+    unpublished, err = store.ListUnpublishedPosts(ctx, "ru")
     require.NoError(t, err)
     assert.Len(t, unpublished, 0)
+
+    unpublished, err = store.ListUnpublishedPosts(ctx, "uk")
+    require.NoError(t, err)
+    require.Len(t, unpublished, 1)
+    assert.Equal(t, "Це тестовий пост", unpublished[0].TranslatedBody)
+}
+
+func TestPost_UseTranslation(t *testing.T) {
+    post := Post{
+        TopComments: []Comment{{Body: "Nice"}, {Body: "Meh"}, {Body: "Late"}},
+        Translations: map[string]Translation{
+            "ru": {Body: "Текст", Comments: []string{"Класс", ""}},
+        },
+    }
+
+    post.UseTranslation("ru")
+    assert.Equal(t, "ru", post.Lang)
+    assert.Equal(t, "Текст", post.TranslatedBody)
+    assert.Equal(t, "Класс", post.TopComments[0].TranslatedBody)
+    assert.Empty(t, post.TopComments[1].TranslatedBody)
+    assert.Empty(t, post.TopComments[2].TranslatedBody)
+
+    // A language without a translation clears the previous one
+    post.UseTranslation("kk")
+    assert.Empty(t, post.TranslatedBody)
+    assert.Empty(t, post.TopComments[0].TranslatedBody)
+}
+
+func TestTranslation_Empty(t *testing.T) {
+    assert.True(t, Translation{}.Empty())
+    assert.True(t, Translation{Comments: []string{"", ""}}.Empty())
+    assert.False(t, Translation{Comments: []string{"", "Класс"}}.Empty())
+    assert.False(t, Translation{Body: "Текст"}.Empty())
 }
\ No newline at end of file
@@ -0,0 +1,115 @@
+package translation
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/w1zzzle/ai-newsbot/internal/config"
+)
+
+// Provider names accepted by TRANSLATION_PROVIDER
+const (
+	// ProviderOpenAI is any OpenAI-compatible chat completions API, such
+	// as OpenRouter, OpenAI, Ollama or a llama.cpp server
+	ProviderOpenAI         = "openai"
+	ProviderLibreTranslate = "libretranslate"
+	ProviderDeepL          = "deepl"
+)
+
+// Factory builds a translation service from the configuration
+type Factory func(cfg *config.Config) (Service, error)
+
+var (
+	providersMu sync.RWMutex
+	providers   = map[string]Factory{
+		ProviderOpenAI:         openAIFromConfig,
+		ProviderLibreTranslate: libreTranslateFromConfig,
+		ProviderDeepL:          deepLFromConfig,
+	}
+)
+
+// Register makes a provider available to FromConfig under name, replacing
+// any provider of the same name
+func Register(name string, factory Factory) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	providers[name] = factory
+}
+
+// Providers returns the names of the registered providers, sorted
+func Providers() []string {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// FromConfig builds the translation service of the configured provider
+func FromConfig(cfg *config.Config) (Service, error) {
+	name := cfg.TranslationProvider
+	if name == "" {
+		name = ProviderOpenAI
+	}
+
+	providersMu.RLock()
+	factory, ok := providers[name]
+	providersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown translation provider %q, want one of %v", name, Providers())
+	}
+
+	service, err := factory(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up translation provider %s: %w", name, err)
+	}
+	return service, nil
+}
+
+// clientConfig collects the settings every HTTP provider shares
+func clientConfig(cfg *config.Config) ClientConfig {
+	return ClientConfig{
+		BaseURL: cfg.TranslationBaseURL,
+		APIKey:  cfg.TranslationAPIKey,
+		Timeout: time.Duration(cfg.TranslationTimeoutSeconds) * time.Second,
+		Headers: cfg.TranslationHeaders,
+	}
+}
+
+func openAIFromConfig(cfg *config.Config) (Service, error) {
+	opts := []Option{
+		WithBaseURL(cfg.TranslationBaseURL),
+		WithModel(cfg.TranslationModel),
+		WithMaxTokens(cfg.TranslationMaxTokens),
+		WithTimeout(time.Duration(cfg.TranslationTimeoutSeconds) * time.Second),
+		WithHeaders(cfg.TranslationHeaders),
+		WithSummaries(cfg.TranslationSummaries...),
+	}
+	if cfg.TranslationTemperature != nil {
+		opts = append(opts, WithTemperature(*cfg.TranslationTemperature))
+	}
+	return New(cfg.TranslationAPIKey, opts...), nil
+}
+
+func libreTranslateFromConfig(cfg *config.Config) (Service, error) {
+	if len(cfg.TranslationSummaries) > 0 {
+		return nil, fmt.Errorf("summaries need a chat model; unset TRANSLATION_SUMMARY_LANGUAGES")
+	}
+	return NewLibreTranslate(clientConfig(cfg)), nil
+}
+
+func deepLFromConfig(cfg *config.Config) (Service, error) {
+	if len(cfg.TranslationSummaries) > 0 {
+		return nil, fmt.Errorf("summaries need a chat model; unset TRANSLATION_SUMMARY_LANGUAGES")
+	}
+	if cfg.TranslationAPIKey == "" && cfg.TranslationBaseURL == "" {
+		return nil, fmt.Errorf("TRANSLATION_API_KEY is required for the DeepL API")
+	}
+	return NewDeepL(clientConfig(cfg)), nil
+}
@@ -0,0 +1,120 @@
+package translation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/w1zzzle/ai-newsbot/internal/config"
+)
+
+func TestFromConfig_Providers(t *testing.T) {
+	tests := []struct {
+		provider string
+		want     any
+	}{
+		{"", &Translator{}},
+		{ProviderOpenAI, &Translator{}},
+		{ProviderLibreTranslate, &LibreTranslate{}},
+		{ProviderDeepL, &DeepL{}},
+	}
+
+	for _, tt := range tests {
+		service, err := FromConfig(&config.Config{TranslationProvider: tt.provider, TranslationAPIKey: "key"})
+		if err != nil {
+			t.Fatalf("Unexpected error for %q: %v", tt.provider, err)
+		}
+		if got, want := fmt.Sprintf("%T", service), fmt.Sprintf("%T", tt.want); got != want {
+			t.Errorf("Provider %q built %s, want %s", tt.provider, got, want)
+		}
+	}
+}
+
+func TestFromConfig_UnknownProvider(t *testing.T) {
+	_, err := FromConfig(&config.Config{TranslationProvider: "babelfish"})
+	if err == nil || !strings.Contains(err.Error(), `unknown translation provider "babelfish"`) {
+		t.Errorf("Expected unknown provider error, got: %v", err)
+	}
+}
+
+func TestFromConfig_SummariesNeedChatModel(t *testing.T) {
+	_, err := FromConfig(&config.Config{
+		TranslationProvider:  ProviderLibreTranslate,
+		TranslationSummaries: []string{"en"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "TRANSLATION_SUMMARY_LANGUAGES") {
+		t.Errorf("Expected summaries error, got: %v", err)
+	}
+}
+
+func TestFromConfig_DeepLNeedsKey(t *testing.T) {
+	_, err := FromConfig(&config.Config{TranslationProvider: ProviderDeepL})
+	if err == nil || !strings.Contains(err.Error(), "TRANSLATION_API_KEY") {
+		t.Errorf("Expected missing key error, got: %v", err)
+	}
+}
+
+func TestFromConfig_LocalOpenAICompatibleServer(t *testing.T) {
+	// An Ollama server: no API key, a local model name
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("Expected /v1/chat/completions, got %s", r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "" {
+			t.Errorf("Expected no Authorization header, got %q", r.Header.Get("Authorization"))
+		}
+
+		var request OpenRouterRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		if request.Model != "qwen2.5:7b" {
+			t.Errorf("Expected model qwen2.5:7b, got %s", request.Model)
+		}
+
+		json.NewEncoder(w).Encode(OpenRouterResponse{
+			Choices: []Choice{{Message: Message{Role: "assistant", Content: "Привіт"}}},
+		})
+	}))
+	defer server.Close()
+
+	service, err := FromConfig(&config.Config{
+		TranslationProvider: ProviderOpenAI,
+		TranslationBaseURL:  server.URL + "/v1",
+		TranslationModel:    "qwen2.5:7b",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	result, err := service.Translate(context.Background(), "Hello", "uk")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result != "Привіт" {
+		t.Errorf("Expected 'Привіт', got %q", result)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	Register("mock", func(cfg *config.Config) (Service, error) {
+		return &MockTranslator{}, nil
+	})
+	defer func() {
+		providersMu.Lock()
+		delete(providers, "mock")
+		providersMu.Unlock()
+	}()
+
+	service, err := FromConfig(&config.Config{TranslationProvider: "mock"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, ok := service.(*MockTranslator); !ok {
+		t.Errorf("Expected the registered provider, got %T", service)
+	}
+}
@@ -0,0 +1,89 @@
+package translation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ClientConfig configures the HTTP client of a translation provider. An
+// empty BaseURL selects the provider's public endpoint.
+type ClientConfig struct {
+	BaseURL string
+	APIKey  string
+	Timeout time.Duration
+	Headers map[string]string
+}
+
+// httpClient returns a client with the configured timeout
+func (c ClientConfig) httpClient() *http.Client {
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &http.Client{Timeout: timeout}
+}
+
+// baseURLOr returns the configured base URL without a trailing slash, or
+// fallback when none is set
+func (c ClientConfig) baseURLOr(fallback string) string {
+	if c.BaseURL == "" {
+		return fallback
+	}
+	return strings.TrimRight(c.BaseURL, "/")
+}
+
+// doJSON sends payload as JSON, or no body when it is nil, and decodes a
+// successful response into out. header is set after the configured
+// headers, so a provider's own headers win.
+func doJSON(ctx context.Context, client *http.Client, method, url string, cfg ClientConfig, header http.Header, payload, out any) error {
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	for name, value := range cfg.Headers {
+		req.Header.Set(name, value)
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return nil
+}
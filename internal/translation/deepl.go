@@ -0,0 +1,145 @@
+package translation
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// DeepL API endpoints. Keys of the free plan end in ":fx" and only work
+// with the free endpoint.
+const (
+	DefaultDeepLURL     = "https://api.deepl.com"
+	DefaultDeepLFreeURL = "https://api-free.deepl.com"
+)
+
+// deeplMaxTexts is how many texts DeepL accepts in one request
+const deeplMaxTexts = 50
+
+// deeplTargets maps language codes to the DeepL target codes that differ
+// from their upper-case form
+var deeplTargets = map[string]string{
+	"en": "EN-US",
+	"pt": "PT-PT",
+}
+
+// DeepL translates with the DeepL API or a server compatible with it
+type DeepL struct {
+	cfg        ClientConfig
+	baseURL    string
+	httpClient *http.Client
+}
+
+type deeplRequest struct {
+	Text       []string `json:"text"`
+	TargetLang string   `json:"target_lang"`
+}
+
+type deeplResponse struct {
+	Translations []struct {
+		DetectedSourceLanguage string `json:"detected_source_language"`
+		Text                   string `json:"text"`
+	} `json:"translations"`
+}
+
+// NewDeepL creates a DeepL client
+func NewDeepL(cfg ClientConfig) *DeepL {
+	fallback := DefaultDeepLURL
+	if strings.HasSuffix(cfg.APIKey, ":fx") {
+		fallback = DefaultDeepLFreeURL
+	}
+	return &DeepL{
+		cfg:        cfg,
+		baseURL:    cfg.baseURLOr(fallback),
+		httpClient: cfg.httpClient(),
+	}
+}
+
+// Translate translates text into targetLang
+func (d *DeepL) Translate(ctx context.Context, text, targetLang string) (string, error) {
+	if strings.TrimSpace(text) == "" {
+		return "", fmt.Errorf("text cannot be empty")
+	}
+
+	results, err := d.translate(ctx, []string{text}, targetLang)
+	if err != nil {
+		return "", err
+	}
+	if results[0] == "" {
+		return "", fmt.Errorf("empty translation returned")
+	}
+	return results[0], nil
+}
+
+// TranslateBatch translates multiple texts into targetLang, up to 50 per
+// request
+func (d *DeepL) TranslateBatch(ctx context.Context, texts []string, targetLang string) ([]string, error) {
+	if len(texts) == 0 {
+		return nil, fmt.Errorf("no texts to translate")
+	}
+
+	var results []string
+	for start := 0; start < len(texts); start += deeplMaxTexts {
+		end := min(start+deeplMaxTexts, len(texts))
+		translated, err := d.translate(ctx, texts[start:end], targetLang)
+		if err != nil {
+			return nil, fmt.Errorf("failed to translate texts %d-%d: %w", start, end-1, err)
+		}
+		results = append(results, translated...)
+	}
+	return results, nil
+}
+
+// IsHealthy checks the API key against the usage endpoint, without
+// spending characters
+func (d *DeepL) IsHealthy(ctx context.Context) error {
+	var usage struct {
+		CharacterCount int `json:"character_count"`
+		CharacterLimit int `json:"character_limit"`
+	}
+	if err := doJSON(ctx, d.httpClient, "GET", d.baseURL+"/v2/usage", d.cfg, d.authHeader(), nil, &usage); err != nil {
+		return fmt.Errorf("health check failed: %w", err)
+	}
+	return nil
+}
+
+func (d *DeepL) translate(ctx context.Context, texts []string, targetLang string) ([]string, error) {
+	if targetLang == "" {
+		return nil, fmt.Errorf("target language cannot be empty")
+	}
+
+	request := deeplRequest{
+		Text:       texts,
+		TargetLang: deeplTarget(targetLang),
+	}
+
+	var response deeplResponse
+	if err := doJSON(ctx, d.httpClient, "POST", d.baseURL+"/v2/translate", d.cfg, d.authHeader(), request, &response); err != nil {
+		return nil, err
+	}
+	if len(response.Translations) != len(texts) {
+		return nil, fmt.Errorf("expected %d translations, got %d", len(texts), len(response.Translations))
+	}
+
+	results := make([]string, len(response.Translations))
+	for i, translation := range response.Translations {
+		results[i] = strings.TrimSpace(translation.Text)
+	}
+	return results, nil
+}
+
+func (d *DeepL) authHeader() http.Header {
+	if d.cfg.APIKey == "" {
+		return nil
+	}
+	return http.Header{"Authorization": {"DeepL-Auth-Key " + d.cfg.APIKey}}
+}
+
+// deeplTarget converts a language code to a DeepL target language
+func deeplTarget(lang string) string {
+	if target, ok := deeplTargets[strings.ToLower(lang)]; ok {
+		return target
+	}
+	return strings.ToUpper(lang)
+}
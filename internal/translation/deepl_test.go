@@ -0,0 +1,140 @@
+package translation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDeepL_Translate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/v2/translate" {
+			t.Errorf("Expected POST /v2/translate, got %s %s", r.Method, r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "DeepL-Auth-Key deepl-key" {
+			t.Errorf("Expected DeepL-Auth-Key authorization, got %q", r.Header.Get("Authorization"))
+		}
+
+		var request deeplRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		if request.TargetLang != "UK" || len(request.Text) != 1 || request.Text[0] != "Hello, world!" {
+			t.Errorf("Unexpected request: %+v", request)
+		}
+
+		w.Write([]byte(`{"translations":[{"detected_source_language":"EN","text":"Привіт, світ!"}]}`))
+	}))
+	defer server.Close()
+
+	client := NewDeepL(ClientConfig{BaseURL: server.URL, APIKey: "deepl-key"})
+
+	result, err := client.Translate(context.Background(), "Hello, world!", "uk")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result != "Привіт, світ!" {
+		t.Errorf("Expected 'Привіт, світ!', got %q", result)
+	}
+}
+
+func TestDeepL_TranslateBatch_SplitsRequests(t *testing.T) {
+	var sizes []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request deeplRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		sizes = append(sizes, len(request.Text))
+
+		var response deeplResponse
+		response.Translations = make([]struct {
+			DetectedSourceLanguage string `json:"detected_source_language"`
+			Text                   string `json:"text"`
+		}, len(request.Text))
+		for i, text := range request.Text {
+			response.Translations[i].Text = "de:" + text
+		}
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	texts := make([]string, 60)
+	for i := range texts {
+		texts[i] = fmt.Sprintf("text %d", i)
+	}
+
+	client := NewDeepL(ClientConfig{BaseURL: server.URL, APIKey: "deepl-key"})
+
+	results, err := client.TranslateBatch(context.Background(), texts, "de")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(sizes) != 2 || sizes[0] != 50 || sizes[1] != 10 {
+		t.Errorf("Expected requests of 50 and 10 texts, got %v", sizes)
+	}
+	if len(results) != 60 || results[59] != "de:text 59" {
+		t.Errorf("Expected 60 results in order, got %d", len(results))
+	}
+}
+
+func TestDeepL_APIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(456)
+		w.Write([]byte(`{"message":"Quota exceeded"}`))
+	}))
+	defer server.Close()
+
+	client := NewDeepL(ClientConfig{BaseURL: server.URL, APIKey: "deepl-key"})
+
+	_, err := client.Translate(context.Background(), "Hello", "uk")
+	if err == nil {
+		t.Fatal("Expected error when the quota is exceeded")
+	}
+	if !strings.Contains(err.Error(), "status 456") {
+		t.Errorf("Expected status in error, got: %v", err)
+	}
+}
+
+func TestDeepL_IsHealthy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/usage" {
+			t.Errorf("Expected /v2/usage, got %s", r.URL.Path)
+		}
+		w.Write([]byte(`{"character_count":180118,"character_limit":1250000}`))
+	}))
+	defer server.Close()
+
+	client := NewDeepL(ClientConfig{BaseURL: server.URL, APIKey: "deepl-key"})
+	if err := client.IsHealthy(context.Background()); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestNewDeepL_FreeKeyUsesFreeEndpoint(t *testing.T) {
+	if client := NewDeepL(ClientConfig{APIKey: "abc:fx"}); client.baseURL != DefaultDeepLFreeURL {
+		t.Errorf("Expected free endpoint, got %s", client.baseURL)
+	}
+	if client := NewDeepL(ClientConfig{APIKey: "abc"}); client.baseURL != DefaultDeepLURL {
+		t.Errorf("Expected pro endpoint, got %s", client.baseURL)
+	}
+}
+
+func TestDeeplTarget(t *testing.T) {
+	tests := map[string]string{
+		"uk":    "UK",
+		"en":    "EN-US",
+		"EN":    "EN-US",
+		"pt":    "PT-PT",
+		"en-gb": "EN-GB",
+	}
+	for lang, expected := range tests {
+		if got := deeplTarget(lang); got != expected {
+			t.Errorf("deeplTarget(%q) = %q, want %q", lang, got, expected)
+		}
+	}
+}
@@ -4,11 +4,11 @@ import "context"
 
 // Service defines the translation service interface
 type Service interface {
-	// TranslateToRussian translates text to Russian
-	TranslateToRussian(ctx context.Context, text string) (string, error)
+	// Translate translates text into targetLang, a language code such as "uk"
+	Translate(ctx context.Context, text, targetLang string) (string, error)
 	
-	// TranslateBatch translates multiple texts to Russian
-	TranslateBatch(ctx context.Context, texts []string) ([]string, error)
+	// TranslateBatch translates multiple texts into targetLang
+	TranslateBatch(ctx context.Context, texts []string, targetLang string) ([]string, error)
 	
 	// IsHealthy checks if the translation service is working
 	IsHealthy(ctx context.Context) error
@@ -0,0 +1,48 @@
+package translation
+
+import "fmt"
+
+// DefaultLanguage is the language posts are translated into unless
+// configured otherwise
+const DefaultLanguage = "ru"
+
+// languageNames spell out the language codes used in prompts. Other codes
+// are passed to the model as they are.
+var languageNames = map[string]string{
+	"be": "Belarusian",
+	"de": "German",
+	"en": "English",
+	"es": "Spanish",
+	"fr": "French",
+	"it": "Italian",
+	"ja": "Japanese",
+	"kk": "Kazakh",
+	"ko": "Korean",
+	"pl": "Polish",
+	"pt": "Portuguese",
+	"ru": "Russian",
+	"tr": "Turkish",
+	"uk": "Ukrainian",
+	"uz": "Uzbek",
+	"zh": "Chinese",
+}
+
+// LanguageName returns the English name of a language code
+func LanguageName(code string) string {
+	if name, ok := languageNames[code]; ok {
+		return name
+	}
+	return code
+}
+
+// prompt asks the model to translate text into lang, or to summarize it
+func prompt(text, lang string, summarize bool) string {
+	if summarize {
+		return fmt.Sprintf("Summarize the following text in %s in two or three sentences. Keep names, numbers and links as they are. Reply with the summary only, without any comments or explanations:\n\n%s", LanguageName(lang), text)
+	}
+	// Russian keeps the prompt the bot was tuned with
+	if lang == "ru" {
+		return fmt.Sprintf("Переведи следующий текст на русский язык. Сохрани оригинальное форматирование и структуру. Переводи только содержание, не добавляй никаких комментариев или пояснений:\n\n%s", text)
+	}
+	return fmt.Sprintf("Translate the following text into %s. Keep the original formatting and structure. Translate only the content, without adding any comments or explanations:\n\n%s", LanguageName(lang), text)
+}
@@ -0,0 +1,120 @@
+package translation
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// DefaultLibreTranslateURL is the public LibreTranslate instance, which
+// requires an API key
+const DefaultLibreTranslateURL = "https://libretranslate.com"
+
+// LibreTranslate translates with a LibreTranslate server, often
+// self-hosted. It detects the source language of every text.
+type LibreTranslate struct {
+	cfg        ClientConfig
+	baseURL    string
+	httpClient *http.Client
+}
+
+type libreTranslateRequest struct {
+	Q      any    `json:"q"`
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Format string `json:"format"`
+	APIKey string `json:"api_key,omitempty"`
+}
+
+type libreTranslateResponse struct {
+	TranslatedText any    `json:"translatedText"`
+	Error          string `json:"error"`
+}
+
+// NewLibreTranslate creates a LibreTranslate client
+func NewLibreTranslate(cfg ClientConfig) *LibreTranslate {
+	return &LibreTranslate{
+		cfg:        cfg,
+		baseURL:    cfg.baseURLOr(DefaultLibreTranslateURL),
+		httpClient: cfg.httpClient(),
+	}
+}
+
+// Translate translates text into targetLang
+func (l *LibreTranslate) Translate(ctx context.Context, text, targetLang string) (string, error) {
+	if strings.TrimSpace(text) == "" {
+		return "", fmt.Errorf("text cannot be empty")
+	}
+
+	translated, err := l.translate(ctx, text, targetLang)
+	if err != nil {
+		return "", err
+	}
+
+	result, ok := translated.(string)
+	if !ok || strings.TrimSpace(result) == "" {
+		return "", fmt.Errorf("empty translation returned")
+	}
+	return strings.TrimSpace(result), nil
+}
+
+// TranslateBatch translates multiple texts into targetLang in one request
+func (l *LibreTranslate) TranslateBatch(ctx context.Context, texts []string, targetLang string) ([]string, error) {
+	if len(texts) == 0 {
+		return nil, fmt.Errorf("no texts to translate")
+	}
+
+	translated, err := l.translate(ctx, texts, targetLang)
+	if err != nil {
+		return nil, err
+	}
+
+	items, ok := translated.([]any)
+	if !ok || len(items) != len(texts) {
+		return nil, fmt.Errorf("expected %d translations", len(texts))
+	}
+
+	results := make([]string, len(items))
+	for i, item := range items {
+		result, _ := item.(string)
+		results[i] = strings.TrimSpace(result)
+	}
+	return results, nil
+}
+
+// IsHealthy checks that the server answers, without spending a translation
+func (l *LibreTranslate) IsHealthy(ctx context.Context) error {
+	var languages []struct {
+		Code string `json:"code"`
+	}
+	if err := doJSON(ctx, l.httpClient, "GET", l.baseURL+"/languages", l.cfg, nil, nil, &languages); err != nil {
+		return fmt.Errorf("health check failed: %w", err)
+	}
+	return nil
+}
+
+// translate sends q, a string or a slice of strings, and returns the
+// translatedText of the same shape
+func (l *LibreTranslate) translate(ctx context.Context, q any, targetLang string) (any, error) {
+	if targetLang == "" {
+		return nil, fmt.Errorf("target language cannot be empty")
+	}
+
+	request := libreTranslateRequest{
+		Q:      q,
+		Source: "auto",
+		Target: targetLang,
+		Format: "text",
+		APIKey: l.cfg.APIKey,
+	}
+
+	var response libreTranslateResponse
+	if err := doJSON(ctx, l.httpClient, "POST", l.baseURL+"/translate", l.cfg, nil, request, &response); err != nil {
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf("API error: %s", response.Error)
+	}
+	return response.TranslatedText, nil
+}
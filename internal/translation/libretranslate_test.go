@@ -0,0 +1,112 @@
+package translation
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLibreTranslate_Translate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/translate" {
+			t.Errorf("Expected POST /translate, got %s %s", r.Method, r.URL.Path)
+		}
+
+		var request map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		if request["q"] != "Hello, world!" || request["target"] != "uk" || request["source"] != "auto" {
+			t.Errorf("Unexpected request: %v", request)
+		}
+		if request["api_key"] != "libre-key" {
+			t.Errorf("Expected api_key in the body, got %v", request["api_key"])
+		}
+
+		json.NewEncoder(w).Encode(map[string]any{"translatedText": "Привіт, світ!"})
+	}))
+	defer server.Close()
+
+	client := NewLibreTranslate(ClientConfig{BaseURL: server.URL + "/", APIKey: "libre-key"})
+
+	result, err := client.Translate(context.Background(), "Hello, world!", "uk")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result != "Привіт, світ!" {
+		t.Errorf("Expected 'Привіт, світ!', got %q", result)
+	}
+}
+
+func TestLibreTranslate_TranslateBatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Q      []string `json:"q"`
+			Target string   `json:"target"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		if len(request.Q) != 2 {
+			t.Fatalf("Expected both texts in one request, got %v", request.Q)
+		}
+
+		json.NewEncoder(w).Encode(map[string]any{"translatedText": []string{"Сәлем", "Әлем"}})
+	}))
+	defer server.Close()
+
+	client := NewLibreTranslate(ClientConfig{BaseURL: server.URL})
+
+	results, err := client.TranslateBatch(context.Background(), []string{"Hello", "World"}, "kk")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(results) != 2 || results[0] != "Сәлем" || results[1] != "Әлем" {
+		t.Errorf("Unexpected results: %v", results)
+	}
+}
+
+func TestLibreTranslate_APIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]any{"error": "xx is not supported"})
+	}))
+	defer server.Close()
+
+	client := NewLibreTranslate(ClientConfig{BaseURL: server.URL})
+
+	_, err := client.Translate(context.Background(), "Hello", "xx")
+	if err == nil {
+		t.Fatal("Expected error for unsupported language")
+	}
+	if !strings.Contains(err.Error(), "status 400") || !strings.Contains(err.Error(), "xx is not supported") {
+		t.Errorf("Expected status and message in error, got: %v", err)
+	}
+}
+
+func TestLibreTranslate_IsHealthy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/languages" {
+			t.Errorf("Expected /languages, got %s", r.URL.Path)
+		}
+		w.Write([]byte(`[{"code":"en","name":"English"},{"code":"uk","name":"Ukrainian"}]`))
+	}))
+	defer server.Close()
+
+	client := NewLibreTranslate(ClientConfig{BaseURL: server.URL})
+	if err := client.IsHealthy(context.Background()); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestLibreTranslate_EmptyText(t *testing.T) {
+	client := NewLibreTranslate(ClientConfig{})
+
+	_, err := client.Translate(context.Background(), "  ", "uk")
+	if err == nil || !strings.Contains(err.Error(), "text cannot be empty") {
+		t.Errorf("Expected 'text cannot be empty' error, got: %v", err)
+	}
+}
@@ -11,18 +11,103 @@ import (
 	"time"
 )
 
-// Translator handles AI-powered translation using OpenRouter
+// Defaults used unless overridden with options
+const (
+	DefaultBaseURL = "https://openrouter.ai/api/v1"
+	DefaultModel   = "deepseek/deepseek-r1-0528:free"
+	DefaultTimeout = 60 * time.Second // DeepSeek R1 can be slower due to reasoning
+)
+
+// Translator handles AI-powered translation using OpenRouter or any other
+// OpenAI-compatible chat completions API, including local Ollama and
+// llama.cpp servers
 type Translator struct {
 	apiKey     string
 	httpClient *http.Client
+	baseURL    string
+	model      string
+	// temperature is nil to leave it to the provider
+	temperature *float64
+	maxTokens   int
+	headers     map[string]string
+	// summarize holds the languages that get a summary instead of a full
+	// translation
+	summarize map[string]bool
+}
+
+// Option configures a Translator
+type Option func(*Translator)
+
+// WithBaseURL sets the API base URL; requests go to its /chat/completions
+func WithBaseURL(baseURL string) Option {
+	return func(t *Translator) {
+		if baseURL != "" {
+			t.baseURL = strings.TrimRight(baseURL, "/")
+		}
+	}
+}
+
+// WithModel sets the model used for translation
+func WithModel(model string) Option {
+	return func(t *Translator) {
+		if model != "" {
+			t.model = model
+		}
+	}
+}
+
+// WithTemperature sets the sampling temperature
+func WithTemperature(temperature float64) Option {
+	return func(t *Translator) {
+		t.temperature = &temperature
+	}
+}
+
+// WithMaxTokens limits the length of a translation. Zero leaves it to the
+// provider.
+func WithMaxTokens(n int) Option {
+	return func(t *Translator) {
+		t.maxTokens = n
+	}
+}
+
+// WithTimeout limits how long a single request may take
+func WithTimeout(d time.Duration) Option {
+	return func(t *Translator) {
+		if d > 0 {
+			t.httpClient.Timeout = d
+		}
+	}
+}
+
+// WithSummaries makes translations into the given languages short
+// summaries, for channels that only want the gist of a post
+func WithSummaries(languages ...string) Option {
+	return func(t *Translator) {
+		for _, lang := range languages {
+			t.summarize[lang] = true
+		}
+	}
+}
+
+// WithHeaders adds headers to every request, replacing defaults of the
+// same name
+func WithHeaders(headers map[string]string) Option {
+	return func(t *Translator) {
+		for name, value := range headers {
+			t.headers[name] = value
+		}
+	}
 }
 
 // OpenRouterRequest represents the request structure for OpenRouter API
 // This matches the OpenAI SDK structure used in the Python example
 type OpenRouterRequest struct {
-	Model    string    `json:"model"`
-	Messages []Message `json:"messages"`
-	Stream   bool      `json:"stream,omitempty"`
+	Model       string    `json:"model"`
+	Messages    []Message `json:"messages"`
+	Stream      bool      `json:"stream,omitempty"`
+	Temperature *float64  `json:"temperature,omitempty"`
+	MaxTokens   int       `json:"max_tokens,omitempty"`
 }
 
 // Message represents a chat message
@@ -50,31 +135,56 @@ type APIError struct {
 }
 
 // New creates a new Translator instance
-func New(apiKey string) *Translator {
-	return &Translator{
+func New(apiKey string, opts ...Option) *Translator {
+	t := &Translator{
 		apiKey: apiKey,
 		httpClient: &http.Client{
-			Timeout: 60 * time.Second, // DeepSeek R1 can be slower due to reasoning
+			Timeout: DefaultTimeout,
+		},
+		baseURL: DefaultBaseURL,
+		model:   DefaultModel,
+		headers: map[string]string{
+			// Optional headers for OpenRouter rankings
+			"HTTP-Referer": "https://github.com/w1zzzle/ai-newsbot",
+			"X-Title":      "AI News Bot",
 		},
+		summarize: make(map[string]bool),
 	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
 }
 
-// TranslateToRussian translates text to Russian using DeepSeek R1
+// TranslateToRussian translates text to Russian using the configured model
+//
+// Deprecated: use Translate with DefaultLanguage.
 func (t *Translator) TranslateToRussian(ctx context.Context, text string) (string, error) {
+	return t.Translate(ctx, text, DefaultLanguage)
+}
+
+// Translate translates text into targetLang, a language code such as "uk",
+// or summarizes it in that language if configured with WithSummaries
+func (t *Translator) Translate(ctx context.Context, text, targetLang string) (string, error) {
 	if strings.TrimSpace(text) == "" {
 		return "", fmt.Errorf("text cannot be empty")
 	}
+	if targetLang == "" {
+		return "", fmt.Errorf("target language cannot be empty")
+	}
 
 	// Prepare the request payload - matches Python SDK structure
 	request := OpenRouterRequest{
-		Model: "deepseek/deepseek-r1-0528:free", // Correct model name from your docs
+		Model: t.model,
 		Messages: []Message{
 			{
 				Role:    "user",
-				Content: fmt.Sprintf("Переведи следующий текст на русский язык. Сохрани оригинальное форматирование и структуру. Переводи только содержание, не добавляй никаких комментариев или пояснений:\n\n%s", text),
+				Content: prompt(text, targetLang, t.summarize[targetLang]),
 			},
 		},
-		Stream: false, // We want complete response, not streaming
+		Stream:      false, // We want complete response, not streaming
+		Temperature: t.temperature,
+		MaxTokens:   t.maxTokens,
 	}
 
 	// Convert to JSON
@@ -84,17 +194,20 @@ func (t *Translator) TranslateToRussian(ctx context.Context, text string) (strin
 	}
 
 	// Create HTTP request
-	req, err := http.NewRequestWithContext(ctx, "POST", "https://openrouter.ai/api/v1/chat/completions", bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", t.baseURL+"/chat/completions", bytes.NewBuffer(jsonData))
 	if err != nil {
 		return "", fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Set headers exactly as in Python example
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+t.apiKey)
-	// Optional headers for OpenRouter rankings (as shown in Python docs)
-	req.Header.Set("HTTP-Referer", "https://github.com/w1zzzle/ai-newsbot")
-	req.Header.Set("X-Title", "AI News Bot")
+	// Local servers such as Ollama need no key
+	if t.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+t.apiKey)
+	}
+	for name, value := range t.headers {
+		req.Header.Set(name, value)
+	}
 
 	// Make the request
 	resp, err := t.httpClient.Do(req)
@@ -138,8 +251,8 @@ func (t *Translator) TranslateToRussian(ctx context.Context, text string) (strin
 	return translatedText, nil
 }
 
-// TranslateBatch translates multiple texts to Russian
-func (t *Translator) TranslateBatch(ctx context.Context, texts []string) ([]string, error) {
+// TranslateBatch translates multiple texts into targetLang
+func (t *Translator) TranslateBatch(ctx context.Context, texts []string, targetLang string) ([]string, error) {
 	if len(texts) == 0 {
 		return nil, fmt.Errorf("no texts to translate")
 	}
@@ -155,7 +268,7 @@ func (t *Translator) TranslateBatch(ctx context.Context, texts []string) ([]stri
 			}
 		}
 
-		translated, err := t.TranslateToRussian(ctx, text)
+		translated, err := t.Translate(ctx, text, targetLang)
 		if err != nil {
 			return nil, fmt.Errorf("failed to translate text %d: %w", i, err)
 		}
@@ -168,7 +281,7 @@ func (t *Translator) TranslateBatch(ctx context.Context, texts []string) ([]stri
 // IsHealthy checks if the translation service is working
 func (t *Translator) IsHealthy(ctx context.Context) error {
 	testText := "Hello, world!"
-	_, err := t.TranslateToRussian(ctx, testText)
+	_, err := t.Translate(ctx, testText, DefaultLanguage)
 	if err != nil {
 		return fmt.Errorf("health check failed: %w", err)
 	}
@@ -34,15 +34,30 @@ func TestTranslateToRussian_Success(t *testing.T) {
 		if r.Method != "POST" {
 			t.Errorf("Expected POST request, got %s", r.Method)
 		}
-		
+
+		if r.URL.Path != "/chat/completions" {
+			t.Errorf("Expected /chat/completions, got %s", r.URL.Path)
+		}
+
 		if r.Header.Get("Content-Type") != "application/json" {
 			t.Errorf("Expected Content-Type application/json")
 		}
-		
-		if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+
+		if r.Header.Get("Authorization") != "Bearer test-api-key" {
 			t.Errorf("Expected Authorization header with Bearer token")
 		}
-		
+
+		var request OpenRouterRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		if request.Model != DefaultModel {
+			t.Errorf("Expected model %s, got %s", DefaultModel, request.Model)
+		}
+		if len(request.Messages) != 1 || !strings.Contains(request.Messages[0].Content, "Hello, world!") {
+			t.Errorf("Expected the text in the prompt, got %+v", request.Messages)
+		}
+
 		// Mock successful response
 		response := OpenRouterResponse{
 			Choices: []Choice{
@@ -54,26 +69,164 @@ func TestTranslateToRussian_Success(t *testing.T) {
 				},
 			},
 		}
-		
+
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(response)
 	}))
 	defer server.Close()
-	
-	// Create translator with mock server URL
-	translator := New("test-api-key")
-	// In real implementation, you'd need to make the URL configurable
-	
+
+	translator := New("test-api-key", WithBaseURL(server.URL))
+
+	result, err := translator.TranslateToRussian(context.Background(), "Hello, world!")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if result != "Привет, мир!" {
+		t.Errorf("Expected 'Привет, мир!', got %q", result)
+	}
+}
+
+func TestTranslateToRussian_Options(t *testing.T) {
+	var request OpenRouterRequest
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		json.NewEncoder(w).Encode(OpenRouterResponse{
+			Choices: []Choice{{Message: Message{Role: "assistant", Content: "Привет"}}},
+		})
+	}))
+	defer server.Close()
+
+	translator := New("test-api-key",
+		WithBaseURL(server.URL+"/"),
+		WithModel("qwen/qwen3-235b-a22b"),
+		WithTemperature(0.2),
+		WithMaxTokens(2048),
+		WithTimeout(5*time.Second),
+		WithHeaders(map[string]string{"X-Title": "Test Bot", "X-Extra": "1"}),
+	)
+
+	if translator.httpClient.Timeout != 5*time.Second {
+		t.Errorf("Expected timeout 5s, got %s", translator.httpClient.Timeout)
+	}
+
+	if _, err := translator.TranslateToRussian(context.Background(), "Hello"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if request.Model != "qwen/qwen3-235b-a22b" {
+		t.Errorf("Expected custom model, got %s", request.Model)
+	}
+	if request.Temperature == nil || *request.Temperature != 0.2 {
+		t.Errorf("Expected temperature 0.2, got %v", request.Temperature)
+	}
+	if request.MaxTokens != 2048 {
+		t.Errorf("Expected max_tokens 2048, got %d", request.MaxTokens)
+	}
+	if header.Get("X-Title") != "Test Bot" || header.Get("X-Extra") != "1" {
+		t.Errorf("Expected custom headers, got %v", header)
+	}
+	if header.Get("HTTP-Referer") == "" {
+		t.Error("Expected default HTTP-Referer header to be kept")
+	}
+}
+
+func TestTranslateToRussian_DefaultsOmitParameters(t *testing.T) {
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		json.NewEncoder(w).Encode(OpenRouterResponse{
+			Choices: []Choice{{Message: Message{Role: "assistant", Content: "Привет"}}},
+		})
+	}))
+	defer server.Close()
+
+	translator := New("test-api-key", WithBaseURL(server.URL))
+	if _, err := translator.TranslateToRussian(context.Background(), "Hello"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// The provider's own defaults apply unless set
+	if _, ok := body["temperature"]; ok {
+		t.Error("Expected temperature to be omitted")
+	}
+	if _, ok := body["max_tokens"]; ok {
+		t.Error("Expected max_tokens to be omitted")
+	}
+}
+
+func TestTranslateToRussian_APIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"error":{"message":"Rate limit exceeded"}}`))
+	}))
+	defer server.Close()
+
+	translator := New("test-api-key", WithBaseURL(server.URL))
+
+	_, err := translator.TranslateToRussian(context.Background(), "Hello")
+	if err == nil {
+		t.Fatal("Expected error for HTTP 429")
+	}
+
+	if !strings.Contains(err.Error(), "status 429") {
+		t.Errorf("Expected status in error, got: %v", err)
+	}
+}
+
+func TestTranslate_TargetLanguage(t *testing.T) {
+	var prompts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request OpenRouterRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		prompts = append(prompts, request.Messages[0].Content)
+		json.NewEncoder(w).Encode(OpenRouterResponse{
+			Choices: []Choice{{Message: Message{Role: "assistant", Content: "Привіт"}}},
+		})
+	}))
+	defer server.Close()
+
+	translator := New("test-api-key", WithBaseURL(server.URL), WithSummaries("en"))
 	ctx := context.Background()
-	
-	// Note: This test would need the translator to be modified to accept a custom URL
-	// For now, this demonstrates the test structure
-	
-	result, err := translator.TranslateToRussian(ctx, "Hello, world!")
-	
-	// This test will fail with real API call, but shows the expected behavior
-	if err != nil && !strings.Contains(err.Error(), "no such host") {
-		t.Errorf("Unexpected error: %v", err)
+
+	for _, lang := range []string{"uk", "kk", "en", "ru", "xx"} {
+		if _, err := translator.Translate(ctx, "Hello, world!", lang); err != nil {
+			t.Fatalf("Unexpected error for %s: %v", lang, err)
+		}
+	}
+
+	expected := []string{
+		"Translate the following text into Ukrainian.",
+		"Translate the following text into Kazakh.",
+		"Summarize the following text in English",
+		"Переведи следующий текст на русский язык.",
+		// Unknown codes reach the model as they are
+		"Translate the following text into xx.",
+	}
+	for i, prefix := range expected {
+		if !strings.HasPrefix(prompts[i], prefix) {
+			t.Errorf("Expected prompt %d to start with %q, got %q", i, prefix, prompts[i])
+		}
+		if !strings.HasSuffix(prompts[i], "Hello, world!") {
+			t.Errorf("Expected prompt %d to end with the text, got %q", i, prompts[i])
+		}
+	}
+}
+
+func TestTranslate_EmptyTargetLanguage(t *testing.T) {
+	translator := New("test-api-key")
+
+	_, err := translator.Translate(context.Background(), "Hello", "")
+	if err == nil || !strings.Contains(err.Error(), "target language cannot be empty") {
+		t.Errorf("Expected 'target language cannot be empty' error, got: %v", err)
 	}
 }
 
@@ -105,7 +258,7 @@ func TestTranslateBatch_EmptySlice(t *testing.T) {
 	translator := New("test-api-key")
 	ctx := context.Background()
 	
-	_, err := translator.TranslateBatch(ctx, []string{})
+	_, err := translator.TranslateBatch(ctx, []string{}, DefaultLanguage)
 	if err == nil {
 		t.Error("Expected error for empty slice")
 	}
@@ -119,7 +272,7 @@ func TestTranslateBatch_NilSlice(t *testing.T) {
 	translator := New("test-api-key")
 	ctx := context.Background()
 	
-	_, err := translator.TranslateBatch(ctx, nil)
+	_, err := translator.TranslateBatch(ctx, nil, DefaultLanguage)
 	if err == nil {
 		t.Error("Expected error for nil slice")
 	}
@@ -158,23 +311,23 @@ func TestContextTimeout(t *testing.T) {
 
 // Mock translator for testing other components
 type MockTranslator struct {
-	TranslateFunc      func(ctx context.Context, text string) (string, error)
-	TranslateBatchFunc func(ctx context.Context, texts []string) ([]string, error)
+	TranslateFunc      func(ctx context.Context, text, targetLang string) (string, error)
+	TranslateBatchFunc func(ctx context.Context, texts []string, targetLang string) ([]string, error)
 	IsHealthyFunc      func(ctx context.Context) error
 }
 
-func (m *MockTranslator) TranslateToRussian(ctx context.Context, text string) (string, error) {
+func (m *MockTranslator) Translate(ctx context.Context, text, targetLang string) (string, error) {
 	if m.TranslateFunc != nil {
-		return m.TranslateFunc(ctx, text)
+		return m.TranslateFunc(ctx, text, targetLang)
 	}
 	return "Переведенный текст", nil
 }
 
-func (m *MockTranslator) TranslateBatch(ctx context.Context, texts []string) ([]string, error) {
+func (m *MockTranslator) TranslateBatch(ctx context.Context, texts []string, targetLang string) ([]string, error) {
 	if m.TranslateBatchFunc != nil {
-		return m.TranslateBatchFunc(ctx, texts)
+		return m.TranslateBatchFunc(ctx, texts, targetLang)
 	}
-	
+
 	results := make([]string, len(texts))
 	for i := range texts {
 		results[i] = "Переведенный текст " + string(rune(i+'1'))
@@ -191,4 +344,4 @@ func (m *MockTranslator) IsHealthy(ctx context.Context) error {
 
 // Test that MockTranslator implements Service interface
 var _ Service = (*MockTranslator)(nil)
-var _ Service = (*Translator)(nil)
\ No newline at end of file
+var _ Service = (*Translator)(nil)
@@ -5,12 +5,83 @@ CREATE TABLE IF NOT EXISTS posts (
     reddit_id TEXT UNIQUE NOT NULL,
     title TEXT NOT NULL,
     body TEXT,
+    score INTEGER NOT NULL DEFAULT 0,
+    num_comments INTEGER NOT NULL DEFAULT 0,
+    permalink TEXT NOT NULL DEFAULT '',
+    url TEXT NOT NULL DEFAULT '',
+    canonical_url TEXT NOT NULL DEFAULT '',
+    title_hash BIGINT NOT NULL DEFAULT 0,
+    duplicate_of TEXT,
+    author TEXT NOT NULL DEFAULT '',
+    subreddit TEXT NOT NULL DEFAULT '',
     media_urls TEXT[],
+    media JSONB NOT NULL DEFAULT '[]',
+    top_comments JSONB NOT NULL DEFAULT '[]',
+    -- translated_body predates the translations table and is no longer written
     translated_body TEXT,
     published_at TIMESTAMPTZ,
     created_at TIMESTAMPTZ DEFAULT NOW()
 );
 
+-- Columns added after the first release, for databases created before them
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS score INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS permalink TEXT NOT NULL DEFAULT '';
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS author TEXT NOT NULL DEFAULT '';
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS subreddit TEXT NOT NULL DEFAULT '';
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS num_comments INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS url TEXT NOT NULL DEFAULT '';
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS top_comments JSONB NOT NULL DEFAULT '[]';
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS media JSONB NOT NULL DEFAULT '[]';
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS canonical_url TEXT NOT NULL DEFAULT '';
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS title_hash BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS duplicate_of TEXT;
+
 CREATE INDEX IF NOT EXISTS idx_posts_reddit_id ON posts(reddit_id);
 CREATE INDEX IF NOT EXISTS idx_posts_published_at ON posts(published_at);
-CREATE INDEX IF NOT EXISTS idx_posts_created_at ON posts(created_at);
\ No newline at end of file
+CREATE INDEX IF NOT EXISTS idx_posts_created_at ON posts(created_at);
+CREATE INDEX IF NOT EXISTS idx_posts_canonical_url ON posts(canonical_url) WHERE canonical_url != '';
+
+-- One translation per post and language, published to that language's channel
+CREATE TABLE IF NOT EXISTS translations (
+    reddit_id TEXT NOT NULL REFERENCES posts(reddit_id) ON DELETE CASCADE,
+    lang TEXT NOT NULL,
+    body TEXT NOT NULL DEFAULT '',
+    comments JSONB NOT NULL DEFAULT '[]',
+    published_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (reddit_id, lang)
+);
+
+CREATE INDEX IF NOT EXISTS idx_translations_unpublished ON translations(lang) WHERE published_at IS NULL;
+
+-- Posts translated before the translations table existed were Russian and
+-- kept their translation in translated_body and top_comments
+INSERT INTO translations (reddit_id, lang, body, comments, published_at)
+SELECT reddit_id, 'ru', COALESCE(translated_body, ''),
+    COALESCE((SELECT jsonb_agg(COALESCE(c->>'translated_body', '')) FROM jsonb_array_elements(top_comments) c), '[]'),
+    published_at
+FROM posts
+WHERE duplicate_of IS NULL
+    AND ((translated_body IS NOT NULL AND translated_body != '')
+        OR EXISTS (SELECT 1 FROM jsonb_array_elements(top_comments) c WHERE c->>'translated_body' != ''))
+ON CONFLICT (reddit_id, lang) DO NOTHING;
+
+-- ETag/Last-Modified values for conditional GET requests to sources
+CREATE TABLE IF NOT EXISTS http_cache (
+    url TEXT PRIMARY KEY,
+    etag TEXT NOT NULL DEFAULT '',
+    last_modified TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- Posts yielded per source and run, used to spot broken selectors
+CREATE TABLE IF NOT EXISTS source_runs (
+    id SERIAL PRIMARY KEY,
+    source TEXT NOT NULL,
+    posts INTEGER NOT NULL,
+    containers INTEGER NOT NULL DEFAULT 0,
+    skipped INTEGER NOT NULL DEFAULT 0,
+    ran_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_source_runs_source_ran_at ON source_runs(source, ran_at DESC);